@@ -0,0 +1,18 @@
+// Package dryrun holds the process-wide dry-run state that is honoured by the
+// helper packages, e.g. to trace commands instead of executing them.
+package dryrun
+
+import "sync/atomic"
+
+//nolint:gochecknoglobals
+var enabled atomic.Bool
+
+// Set enables or disables the dry-run mode.
+func Set(v bool) {
+	enabled.Store(v)
+}
+
+// Enabled returns whether the dry-run mode is enabled.
+func Enabled() bool {
+	return enabled.Load()
+}
@@ -1,32 +1,46 @@
 package exec
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"strings"
 
+	"github.com/thegeeklab/wp-plugin-go/v6/dryrun"
+	"github.com/thegeeklab/wp-plugin-go/v6/internal/execstub"
 	"golang.org/x/sys/execabs"
 )
 
+var (
+	ErrStdoutSet = errors.New("exec: Stdout already set")
+	ErrStderrSet = errors.New("exec: Stderr already set")
+)
+
 // Cmd represents a command to be executed, with options to control its behavior.
 // The Cmd struct embeds the standard library's exec.Cmd, adding additional fields
-// to control the command's output and tracing.
+// to control the command's output and tracing. All execution methods, Run,
+// Start, Output and CombinedOutput, honour dry-run mode, tracing and stubs.
 type Cmd struct {
 	*exec.Cmd
 	Trace       bool      // Print composed command before execution.
 	TraceWriter io.Writer // Where to write the trace output.
+	DryRun      bool      // Print composed command but do not execute it.
+
+	// Set by Start if the process was not started due to dry-run mode or a
+	// stub installed by the plugintest harness, the result is returned by Wait.
+	skipped bool
+	stubErr error
 }
 
 // Run runs the command and waits for it to complete.
 // If there is an error starting the command, it is returned.
 // Otherwise, the command is waited for and its exit status is returned.
+// In dry-run mode the command is traced but not executed.
 func (c *Cmd) Run() error {
-	if c.Trace {
-		fmt.Fprintf(c.TraceWriter, "+ %s\n", strings.Join(c.Args, " "))
-	}
-
 	if err := c.Start(); err != nil {
 		return err
 	}
@@ -34,14 +48,129 @@ func (c *Cmd) Run() error {
 	return c.Wait()
 }
 
+// Start starts the command but does not wait for it to complete. In dry-run
+// mode the command is traced but not started, Wait returns immediately.
+func (c *Cmd) Start() error {
+	if c.DryRun {
+		c.trace("+ [dry-run] %s\n")
+		c.skipped = true
+
+		return nil
+	}
+
+	if c.Trace {
+		c.trace("+ %s\n")
+	}
+
+	if fn := execstub.Load(); fn != nil {
+		c.skipped = true
+		c.stubErr = fn(c.Cmd)
+
+		return nil
+	}
+
+	return c.Cmd.Start()
+}
+
+// Wait waits for the command started by Start to exit. If the command was not
+// executed due to dry-run mode or a stub, the result of the stub is returned.
+func (c *Cmd) Wait() error {
+	if c.skipped {
+		return c.stubErr
+	}
+
+	return c.Cmd.Wait()
+}
+
+// Output runs the command and returns its standard output, see
+// exec.Cmd.Output. In dry-run mode the output is empty.
+func (c *Cmd) Output() ([]byte, error) {
+	if c.Stdout != nil {
+		return nil, ErrStdoutSet
+	}
+
+	var stdout bytes.Buffer
+
+	c.Stdout = &stdout
+
+	var stderr *bytes.Buffer
+
+	if c.Stderr == nil {
+		stderr = new(bytes.Buffer)
+		c.Stderr = stderr
+	}
+
+	err := c.Run()
+
+	var exitErr *exec.ExitError
+	if stderr != nil && errors.As(err, &exitErr) {
+		exitErr.Stderr = stderr.Bytes()
+	}
+
+	return stdout.Bytes(), err
+}
+
+// CombinedOutput runs the command and returns its combined standard output
+// and standard error, see exec.Cmd.CombinedOutput. In dry-run mode the output
+// is empty.
+func (c *Cmd) CombinedOutput() ([]byte, error) {
+	if c.Stdout != nil {
+		return nil, ErrStdoutSet
+	}
+
+	if c.Stderr != nil {
+		return nil, ErrStderrSet
+	}
+
+	var output bytes.Buffer
+
+	c.Stdout = &output
+	c.Stderr = &output
+
+	err := c.Run()
+
+	return output.Bytes(), err
+}
+
+func (c *Cmd) trace(format string) {
+	w := c.TraceWriter
+	if w == nil {
+		w = os.Stdout
+	}
+
+	args := strings.Join(c.Args, " ")
+	if fn := redact.Load(); fn != nil && *fn != nil {
+		args = (*fn)(args)
+	}
+
+	fmt.Fprintf(w, format, args)
+}
+
 // Command creates a new Cmd with the given name and arguments. The Cmd is configured
 // with Trace set to true and TraceWriter set to os.Stdout. The Cmd's Env is set
-// to the current environment.
+// to the current environment. DryRun is enabled if the global dry-run mode is set.
 func Command(name string, arg ...string) *Cmd {
 	cmd := &Cmd{
 		Cmd:         execabs.Command(name, arg...),
 		Trace:       true,
 		TraceWriter: os.Stdout,
+		DryRun:      dryrun.Enabled(),
+	}
+
+	cmd.Env = os.Environ()
+
+	return cmd
+}
+
+// CommandContext is like Command but includes a context. The provided context
+// is used to kill the process if the context becomes done before the command
+// completes on its own.
+func CommandContext(ctx context.Context, name string, arg ...string) *Cmd {
+	cmd := &Cmd{
+		Cmd:         execabs.CommandContext(ctx, name, arg...),
+		Trace:       true,
+		TraceWriter: os.Stdout,
+		DryRun:      dryrun.Enabled(),
 	}
 
 	cmd.Env = os.Environ()
@@ -3,9 +3,12 @@ package exec
 import (
 	"bytes"
 	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/thegeeklab/wp-plugin-go/v6/internal/execstub"
 )
 
 func TestCmdRun(t *testing.T) {
@@ -71,6 +74,18 @@ func TestCmdRun(t *testing.T) {
 			wantTrace:  "+ sh -c echo error >&2\n",
 			wantStderr: "error\n",
 		},
+		{
+			name: "dry run",
+			cmd: &Cmd{
+				Trace:  false,
+				DryRun: true,
+				Cmd: &exec.Cmd{
+					Path: echoPath,
+					Args: []string{"echo", "hello"},
+				},
+			},
+			wantTrace: "+ [dry-run] echo hello\n",
+		},
 		{
 			name: "error",
 			cmd: &Cmd{
@@ -106,3 +121,173 @@ func TestCmdRun(t *testing.T) {
 		})
 	}
 }
+
+func TestCmdRunStub(t *testing.T) {
+	var got []string
+
+	restore := execstub.Set(func(cmd *exec.Cmd) error {
+		got = cmd.Args
+
+		_, err := cmd.Stdout.Write([]byte("stubbed\n"))
+
+		return err
+	})
+
+	stdoutBuf := new(bytes.Buffer)
+	traceBuf := new(bytes.Buffer)
+
+	cmd := Command("git", "status")
+	cmd.Stdout = stdoutBuf
+	cmd.TraceWriter = traceBuf
+
+	assert.NoError(t, cmd.Run())
+	assert.Equal(t, []string{"git", "status"}, got)
+	assert.Equal(t, "stubbed\n", stdoutBuf.String())
+	assert.Equal(t, "+ git status\n", traceBuf.String())
+
+	restore()
+
+	assert.Nil(t, execstub.Load())
+}
+
+func TestCmdTraceRedact(t *testing.T) {
+	restoreStub := execstub.Set(func(_ *exec.Cmd) error { return nil })
+	defer restoreStub()
+
+	restore := SetRedactFunc(func(s string) string {
+		return strings.ReplaceAll(s, "s3cr3t", "********")
+	})
+
+	traceBuf := new(bytes.Buffer)
+
+	cmd := Command("curl", "-u", "user:s3cr3t", "https://example.com")
+	cmd.TraceWriter = traceBuf
+
+	assert.NoError(t, cmd.Run())
+	assert.Equal(t, "+ curl -u user:******** https://example.com\n", traceBuf.String())
+
+	restore()
+
+	assert.Nil(t, redact.Load())
+}
+
+func TestCmdDryRun(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(cmd *Cmd) ([]byte, error)
+	}{
+		{
+			name: "run",
+			run:  func(cmd *Cmd) ([]byte, error) { return nil, cmd.Run() },
+		},
+		{
+			name: "start",
+			run: func(cmd *Cmd) ([]byte, error) {
+				if err := cmd.Start(); err != nil {
+					return nil, err
+				}
+
+				return nil, cmd.Wait()
+			},
+		},
+		{
+			name: "output",
+			run:  func(cmd *Cmd) ([]byte, error) { return cmd.Output() },
+		},
+		{
+			name: "combined output",
+			run:  func(cmd *Cmd) ([]byte, error) { return cmd.CombinedOutput() },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "file")
+			traceBuf := new(bytes.Buffer)
+
+			cmd := Command("touch", path)
+			cmd.DryRun = true
+			cmd.TraceWriter = traceBuf
+
+			out, err := tt.run(cmd)
+
+			assert.NoError(t, err)
+			assert.Empty(t, out)
+			assert.NoFileExists(t, path)
+			assert.Equal(t, "+ [dry-run] touch "+path+"\n", traceBuf.String())
+		})
+	}
+}
+
+func TestCmdOutput(t *testing.T) {
+	traceBuf := new(bytes.Buffer)
+
+	cmd := Command("sh", "-c", "echo out; echo err >&2")
+	cmd.TraceWriter = traceBuf
+
+	out, err := cmd.Output()
+	assert.NoError(t, err)
+	assert.Equal(t, "out\n", string(out))
+	assert.Equal(t, "+ sh -c echo out; echo err >&2\n", traceBuf.String())
+
+	cmd = Command("sh", "-c", "echo out; echo err >&2")
+	cmd.TraceWriter = new(bytes.Buffer)
+
+	out, err = cmd.CombinedOutput()
+	assert.NoError(t, err)
+	assert.Equal(t, "out\nerr\n", string(out))
+
+	cmd = Command("sh", "-c", "echo err >&2; exit 2")
+	cmd.TraceWriter = new(bytes.Buffer)
+
+	_, err = cmd.Output()
+
+	var exitErr *exec.ExitError
+
+	assert.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, "err\n", string(exitErr.Stderr))
+
+	cmd = Command("true")
+	cmd.Stdout = new(bytes.Buffer)
+
+	_, err = cmd.Output()
+	assert.ErrorIs(t, err, ErrStdoutSet)
+}
+
+func TestCmdStubOutput(t *testing.T) {
+	restore := execstub.Set(func(cmd *exec.Cmd) error {
+		if cmd.Stdout != nil {
+			_, _ = cmd.Stdout.Write([]byte("stubbed out\n"))
+		}
+
+		if cmd.Stderr != nil {
+			_, _ = cmd.Stderr.Write([]byte("stubbed err\n"))
+		}
+
+		return nil
+	})
+	defer restore()
+
+	path := filepath.Join(t.TempDir(), "file")
+
+	cmd := Command("touch", path)
+	cmd.TraceWriter = new(bytes.Buffer)
+
+	out, err := cmd.CombinedOutput()
+	assert.NoError(t, err)
+	assert.Equal(t, "stubbed out\nstubbed err\n", string(out))
+
+	cmd = Command("touch", path)
+	cmd.TraceWriter = new(bytes.Buffer)
+
+	out, err = cmd.Output()
+	assert.NoError(t, err)
+	assert.Equal(t, "stubbed out\n", string(out))
+
+	cmd = Command("touch", path)
+	cmd.TraceWriter = new(bytes.Buffer)
+
+	assert.NoError(t, cmd.Start())
+	assert.NoError(t, cmd.Wait())
+	assert.NoFileExists(t, path)
+}
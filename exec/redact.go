@@ -0,0 +1,22 @@
+package exec
+
+import (
+	"sync/atomic"
+)
+
+// RedactFunc replaces sensitive values in the given string.
+type RedactFunc func(s string) string
+
+//nolint:gochecknoglobals
+var redact atomic.Pointer[RedactFunc]
+
+// SetRedactFunc sets the function that is applied to all command traces until
+// the returned restore function is called, e.g. to mask secrets passed as
+// command arguments.
+func SetRedactFunc(fn RedactFunc) func() {
+	prev := redact.Swap(&fn)
+
+	return func() {
+		redact.Store(prev)
+	}
+}
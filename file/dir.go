@@ -4,16 +4,26 @@ import (
 	"errors"
 	"io"
 	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/thegeeklab/wp-plugin-go/v6/dryrun"
 )
 
 // DeleteDir deletes the directory at the given path.
 // It returns nil if the deletion succeeds, or the deletion error otherwise.
 // If the directory does not exist, DeleteDir returns nil.
+// In dry-run mode the intended deletion is logged but not performed.
 func DeleteDir(path string) error {
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		return nil
 	}
 
+	if dryrun.Enabled() {
+		log.Info().Str("path", path).Msg("dry-run: skip deleting directory")
+
+		return nil
+	}
+
 	return os.Remove(path)
 }
 
@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+
+	"github.com/rs/zerolog/log"
+	"github.com/thegeeklab/wp-plugin-go/v6/dryrun"
 )
 
 // The MSDN docs appear to say that a normal path that is 248 bytes long will work;
@@ -56,7 +59,16 @@ func ExpandFileList(fileList []string) ([]string, error) {
 }
 
 // WriteTmpFile creates a temporary file with the given name and content, and returns the path to the created file.
+// In dry-run mode the intended write is logged and the path the file would have been written to is returned.
 func WriteTmpFile(name, content string) (string, error) {
+	if dryrun.Enabled() {
+		path := filepath.Join(os.TempDir(), name)
+
+		log.Info().Str("path", path).Int("size", len(content)).Msg("dry-run: skip writing temporary file")
+
+		return path, nil
+	}
+
 	tmpfile, err := os.CreateTemp("", name)
 	if err != nil {
 		return "", err
@@ -72,3 +84,39 @@ func WriteTmpFile(name, content string) (string, error) {
 
 	return tmpfile.Name(), nil
 }
+
+// WriteFileAtomic writes the data to the file at the given path. The data is written to a
+// temporary file in the same directory first, which is then renamed to the target path. Readers
+// either see the previous or the complete new content, but never a partially written file.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmpfile, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	tmpName := tmpfile.Name()
+
+	defer os.Remove(tmpName)
+
+	if _, err := tmpfile.Write(data); err != nil {
+		tmpfile.Close()
+
+		return err
+	}
+
+	if err := tmpfile.Sync(); err != nil {
+		tmpfile.Close()
+
+		return err
+	}
+
+	if err := tmpfile.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(tmpName, perm); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpName, path)
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/thegeeklab/wp-plugin-go/v6/dryrun"
 )
 
 const helloWorld = "Hello, World!"
@@ -62,3 +63,51 @@ func TestWriteTmpFile(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteTmpFileDryRun(t *testing.T) {
+	dryrun.Set(true)
+	defer dryrun.Set(false)
+
+	tmpFile, err := WriteTmpFile("dry-run.txt", helloWorld)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(os.TempDir(), "dry-run.txt"), tmpFile)
+	assert.NoFileExists(t, tmpFile)
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	t.Run("write new file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "out.env")
+
+		assert.NoError(t, WriteFileAtomic(path, []byte(helloWorld), 0o640))
+
+		data, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, helloWorld, string(data))
+
+		info, err := os.Stat(path)
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+	})
+
+	t.Run("replace existing file", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "out.env")
+
+		assert.NoError(t, os.WriteFile(path, []byte("old"), 0o600))
+		assert.NoError(t, WriteFileAtomic(path, []byte("new"), 0o600))
+
+		data, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, "new", string(data))
+
+		entries, err := os.ReadDir(dir)
+		assert.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "non-existent", "out.env")
+
+		assert.Error(t, WriteFileAtomic(path, []byte(helloWorld), 0o600))
+	})
+}
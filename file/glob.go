@@ -0,0 +1,98 @@
+package file
+
+import (
+	"path"
+	"strings"
+)
+
+const globstar = "**"
+
+// Match reports whether the slash-separated path name matches the pattern.
+// The pattern syntax is the same as in path.Match, in addition a `**` path
+// segment matches zero or more path segments, e.g. `docs/**/*.md` matches
+// `docs/index.md` and `docs/guide/setup.md`. Patterns are anchored to the
+// start of the path, use `**/*.md` to match files in any directory.
+// The only possible returned error is path.ErrBadPattern.
+func Match(pattern, name string) (bool, error) {
+	patternSegments := strings.Split(strings.Trim(pattern, "/"), "/")
+
+	for _, segment := range patternSegments {
+		if _, err := path.Match(segment, ""); err != nil {
+			return false, err
+		}
+	}
+
+	return matchSegments(patternSegments, strings.Split(strings.Trim(name, "/"), "/")), nil
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == globstar {
+			pattern = pattern[1:]
+
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern, name[i:]) {
+					return true
+				}
+			}
+
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
+
+// MatchPaths returns the paths that match any of the include patterns and
+// none of the exclude patterns. If no include patterns are given, all paths
+// are included.
+func MatchPaths(paths, include, exclude []string) ([]string, error) {
+	matched := make([]string, 0)
+
+	for _, name := range paths {
+		ok, err := matchAny(include, name, true)
+		if err != nil {
+			return nil, err
+		}
+
+		if !ok {
+			continue
+		}
+
+		excluded, err := matchAny(exclude, name, false)
+		if err != nil {
+			return nil, err
+		}
+
+		if !excluded {
+			matched = append(matched, name)
+		}
+	}
+
+	return matched, nil
+}
+
+func matchAny(patterns []string, name string, empty bool) (bool, error) {
+	if len(patterns) == 0 {
+		return empty, nil
+	}
+
+	for _, pattern := range patterns {
+		ok, err := Match(pattern, name)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,93 @@
+package file
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{pattern: "README.md", name: "README.md", want: true},
+		{pattern: "*.md", name: "README.md", want: true},
+		{pattern: "*.md", name: "docs/index.md", want: false},
+		{pattern: "docs/*", name: "docs/index.md", want: true},
+		{pattern: "docs/*", name: "docs/guide/index.md", want: false},
+		{pattern: "docs/**", name: "docs/guide/index.md", want: true},
+		{pattern: "docs/**", name: "docs", want: true},
+		{pattern: "docs/**/*.md", name: "docs/index.md", want: true},
+		{pattern: "docs/**/*.md", name: "docs/a/b/c.md", want: true},
+		{pattern: "docs/**/*.md", name: "docs/a/b/c.txt", want: false},
+		{pattern: "**/*.go", name: "main.go", want: true},
+		{pattern: "**/*.go", name: "cmd/app/main.go", want: true},
+		{pattern: "**/testdata/**", name: "pkg/testdata/file.json", want: true},
+		{pattern: "**", name: "any/path/at/all", want: true},
+		{pattern: "src/[a-c]?.go", name: "src/ab.go", want: true},
+		{pattern: "/docs/*.md", name: "docs/index.md", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.name, func(t *testing.T) {
+			got, err := Match(tt.pattern, tt.name)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := Match("docs/[", "docs/a")
+	assert.ErrorIs(t, err, path.ErrBadPattern)
+}
+
+func TestMatchPaths(t *testing.T) {
+	paths := []string{"README.md", "docs/index.md", "docs/guide/setup.md", "main.go", "pkg/util_test.go"}
+
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		want    []string
+	}{
+		{
+			name: "no patterns",
+			want: paths,
+		},
+		{
+			name:    "include",
+			include: []string{"docs/**", "*.md"},
+			want:    []string{"README.md", "docs/index.md", "docs/guide/setup.md"},
+		},
+		{
+			name:    "include and exclude",
+			include: []string{"**/*.go"},
+			exclude: []string{"**/*_test.go"},
+			want:    []string{"main.go"},
+		},
+		{
+			name:    "exclude only",
+			exclude: []string{"docs/**"},
+			want:    []string{"README.md", "main.go", "pkg/util_test.go"},
+		},
+		{
+			name:    "no match",
+			include: []string{"charts/**"},
+			want:    []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MatchPaths(paths, tt.include, tt.exclude)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := MatchPaths(paths, nil, []string{"["})
+	assert.ErrorIs(t, err, path.ErrBadPattern)
+}
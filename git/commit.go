@@ -0,0 +1,172 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Signature is the author or committer of a commit.
+type Signature struct {
+	Name  string
+	Email string
+	When  time.Time
+}
+
+// Commit is a parsed commit object.
+type Commit struct {
+	SHA       string
+	Tree      string
+	Parents   []string
+	Author    Signature
+	Committer Signature
+	Message   string
+}
+
+// Tag is a parsed annotated tag object.
+type Tag struct {
+	Object  string
+	Type    ObjectType
+	Name    string
+	Tagger  Signature
+	Message string
+}
+
+// Commit reads the commit with the given SHA.
+func (r *Repository) Commit(sha string) (*Commit, error) {
+	obj, err := r.Object(sha)
+	if err != nil {
+		return nil, err
+	}
+
+	if obj.Type != ObjectCommit {
+		return nil, fmt.Errorf("%w: %s is a %s, not a commit", ErrInvalidObject, sha, obj.Type)
+	}
+
+	commit := &Commit{SHA: sha}
+
+	headers, message := splitObject(obj.Data)
+	commit.Message = message
+
+	for _, header := range headers {
+		switch header.key {
+		case "tree":
+			commit.Tree = header.value
+		case "parent":
+			commit.Parents = append(commit.Parents, header.value)
+		case "author":
+			commit.Author = parseSignature(header.value)
+		case "committer":
+			commit.Committer = parseSignature(header.value)
+		}
+	}
+
+	return commit, nil
+}
+
+func parseTag(data []byte) (*Tag, error) {
+	tag := &Tag{}
+
+	headers, message := splitObject(data)
+	tag.Message = message
+
+	for _, header := range headers {
+		switch header.key {
+		case "object":
+			tag.Object = header.value
+		case "type":
+			tag.Type = ObjectType(header.value)
+		case "tag":
+			tag.Name = header.value
+		case "tagger":
+			tag.Tagger = parseSignature(header.value)
+		}
+	}
+
+	if tag.Object == "" {
+		return nil, fmt.Errorf("%w: tag without object", ErrInvalidObject)
+	}
+
+	return tag, nil
+}
+
+type objectHeader struct {
+	key   string
+	value string
+}
+
+// splitObject splits a commit or tag object into its headers and message.
+// Continuation lines of multi-line headers like gpgsig are appended to the
+// previous header.
+func splitObject(data []byte) ([]objectHeader, string) {
+	head, message, _ := bytes.Cut(data, []byte("\n\n"))
+	headers := make([]objectHeader, 0)
+
+	for line := range strings.SplitSeq(string(head), "\n") {
+		if strings.HasPrefix(line, " ") && len(headers) > 0 {
+			headers[len(headers)-1].value += "\n" + line[1:]
+
+			continue
+		}
+
+		key, value, _ := strings.Cut(line, " ")
+		headers = append(headers, objectHeader{key: key, value: value})
+	}
+
+	return headers, string(message)
+}
+
+// parseSignature parses a signature in the form "Name <email> timestamp zone".
+func parseSignature(value string) Signature {
+	var sig Signature
+
+	start := strings.LastIndex(value, "<")
+	end := strings.LastIndex(value, ">")
+
+	if start < 0 || end < start {
+		sig.Name = strings.TrimSpace(value)
+
+		return sig
+	}
+
+	sig.Name = strings.TrimSpace(value[:start])
+	sig.Email = value[start+1 : end]
+
+	fields := strings.Fields(value[end+1:])
+	if len(fields) != 2 { //nolint:mnd
+		return sig
+	}
+
+	seconds, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return sig
+	}
+
+	sig.When = time.Unix(seconds, 0).In(parseZone(fields[1]))
+
+	return sig
+}
+
+// parseZone parses a time zone offset in the form +hhmm or -hhmm.
+func parseZone(zone string) *time.Location {
+	//nolint:mnd
+	if len(zone) != 5 {
+		return time.UTC
+	}
+
+	hours, err1 := strconv.Atoi(zone[1:3])
+	minutes, err2 := strconv.Atoi(zone[3:5])
+
+	if err1 != nil || err2 != nil {
+		return time.UTC
+	}
+
+	offset := (hours*60 + minutes) * 60 //nolint:mnd
+	if zone[0] == '-' {
+		offset = -offset
+	}
+
+	return time.FixedZone(zone, offset)
+}
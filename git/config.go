@@ -0,0 +1,157 @@
+package git
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultRemote is the name of the remote that is preferred if present.
+const DefaultRemote = "origin"
+
+// Remote is a configured remote of a repository.
+type Remote struct {
+	Name string
+	URL  string
+}
+
+// Remotes returns the remotes configured in the repository config in the order
+// of their definition.
+func (r *Repository) Remotes() ([]Remote, error) {
+	file, err := os.Open(filepath.Join(r.CommonDir, "config"))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var (
+		remotes []Remote
+		current = -1
+	)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			current = -1
+
+			section := strings.TrimSuffix(strings.TrimPrefix(line, "["), "]")
+			if name, ok := strings.CutPrefix(section, "remote "); ok {
+				remotes = append(remotes, Remote{Name: strings.Trim(name, `"`)})
+				current = len(remotes) - 1
+			}
+
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || current < 0 || !strings.EqualFold(strings.TrimSpace(key), "url") {
+			continue
+		}
+
+		if remotes[current].URL == "" {
+			remotes[current].URL = strings.Trim(strings.TrimSpace(value), `"`)
+		}
+	}
+
+	return remotes, scanner.Err()
+}
+
+// Remote returns the default remote, or the first remote if there is no
+// default remote.
+func (r *Repository) Remote() (Remote, error) {
+	remotes, err := r.Remotes()
+	if err != nil {
+		return Remote{}, err
+	}
+
+	for _, remote := range remotes {
+		if remote.Name == DefaultRemote {
+			return remote, nil
+		}
+	}
+
+	if len(remotes) == 0 {
+		return Remote{}, fmt.Errorf("%w: no remote configured", ErrRefNotFound)
+	}
+
+	return remotes[0], nil
+}
+
+// RemoteInfo is the information derived from a remote URL.
+type RemoteInfo struct {
+	// Host of the remote.
+	Host string
+	// Owner of the repository, may contain multiple path elements for nested
+	// groups.
+	Owner string
+	// Name of the repository without .git suffix.
+	Name string
+}
+
+// Slug returns the owner and name of the repository joined by a slash.
+func (i RemoteInfo) Slug() string {
+	if i.Owner == "" {
+		return i.Name
+	}
+
+	return i.Owner + "/" + i.Name
+}
+
+// WebURL returns the HTTPS URL of the repository.
+func (i RemoteInfo) WebURL() string {
+	if i.Host == "" {
+		return ""
+	}
+
+	return (&url.URL{Scheme: "https", Host: i.Host, Path: "/" + i.Slug()}).String()
+}
+
+// ParseRemoteURL parses an HTTP(S), SSH or scp-like remote URL, e.g.
+// git@example.com:octocat/hello-world.git.
+func ParseRemoteURL(remote string) (RemoteInfo, error) {
+	var host, path string
+
+	if strings.Contains(remote, "://") {
+		u, err := url.Parse(remote)
+		if err != nil {
+			return RemoteInfo{}, err
+		}
+
+		host, path = u.Hostname(), u.Path
+	} else {
+		hostPart, pathPart, ok := strings.Cut(remote, ":")
+		if !ok {
+			return RemoteInfo{}, fmt.Errorf("invalid remote url %q", remote) //nolint:err113
+		}
+
+		_, host, ok = strings.Cut(hostPart, "@")
+		if !ok {
+			host = hostPart
+		}
+
+		path = pathPart
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+
+	info := RemoteInfo{Host: host, Name: path}
+
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		info.Owner, info.Name = path[:i], path[i+1:]
+	}
+
+	if info.Name == "" {
+		return RemoteInfo{}, fmt.Errorf("invalid remote url %q", remote) //nolint:err113
+	}
+
+	return info, nil
+}
@@ -0,0 +1,247 @@
+package git
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// ObjectType defines the type of a git object.
+type ObjectType string
+
+const (
+	ObjectCommit ObjectType = "commit"
+	ObjectTree   ObjectType = "tree"
+	ObjectBlob   ObjectType = "blob"
+	ObjectTag    ObjectType = "tag"
+)
+
+// Delta and size encoding.
+const (
+	deltaCopy        = 0x80
+	deltaCopyMaxSize = 0x10000
+	varintContinue   = 0x80
+	varintMask       = 0x7f
+	varintShift      = 7
+	byteShift        = 8
+)
+
+var (
+	ErrObjectNotFound = errors.New("object not found")
+	ErrInvalidObject  = errors.New("invalid object")
+)
+
+// Object is a decompressed git object.
+type Object struct {
+	Type ObjectType
+	Data []byte
+}
+
+// Object reads the object with the given SHA from the loose objects or the
+// pack files of the repository.
+func (r *Repository) Object(sha string) (*Object, error) {
+	return r.object(sha, 0)
+}
+
+func (r *Repository) object(sha string, depth int) (*Object, error) {
+	id, err := hex.DecodeString(sha)
+	if err != nil || len(id) != sha1Size {
+		return nil, fmt.Errorf("%w: invalid sha %q", ErrInvalidObject, sha)
+	}
+
+	obj, err := r.looseObject(sha)
+	if err == nil || !errors.Is(err, fs.ErrNotExist) {
+		return obj, err
+	}
+
+	packs, err := r.loadPacks()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range packs {
+		offset, ok, err := p.find(id)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			return p.object(r, offset, depth)
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrObjectNotFound, sha)
+}
+
+func (r *Repository) objectsDir() string {
+	return filepath.Join(r.CommonDir, "objects")
+}
+
+// looseObject reads a zlib compressed object stored in its own file.
+func (r *Repository) looseObject(sha string) (*Object, error) {
+	file, err := os.Open(filepath.Join(r.objectsDir(), sha[:2], sha[2:]))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	zr, err := zlib.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidObject, sha, err)
+	}
+	defer zr.Close()
+
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidObject, sha, err)
+	}
+
+	header, content, ok := bytes.Cut(data, []byte{0})
+	if !ok {
+		return nil, fmt.Errorf("%w: %s: missing header", ErrInvalidObject, sha)
+	}
+
+	typ, size, ok := bytes.Cut(header, []byte{' '})
+	if !ok {
+		return nil, fmt.Errorf("%w: %s: invalid header", ErrInvalidObject, sha)
+	}
+
+	if n, err := strconv.Atoi(string(size)); err != nil || n != len(content) {
+		return nil, fmt.Errorf("%w: %s: size mismatch", ErrInvalidObject, sha)
+	}
+
+	return &Object{Type: ObjectType(typ), Data: content}, nil
+}
+
+// loadPacks opens the indexes of all pack files once.
+func (r *Repository) loadPacks() ([]*pack, error) {
+	if r.packs != nil {
+		return r.packs, nil
+	}
+
+	paths, err := filepath.Glob(filepath.Join(r.objectsDir(), "pack", "pack-*.idx"))
+	if err != nil {
+		return nil, err
+	}
+
+	r.packs = make([]*pack, 0, len(paths))
+
+	for _, path := range paths {
+		p, err := openPack(path)
+		if err != nil {
+			return nil, err
+		}
+
+		r.packs = append(r.packs, p)
+	}
+
+	return r.packs, nil
+}
+
+// applyDelta builds an object from the base object and the delta instructions.
+func applyDelta(base, delta []byte) ([]byte, error) {
+	srcSize, delta, err := deltaSize(delta)
+	if err != nil {
+		return nil, err
+	}
+
+	if srcSize != len(base) {
+		return nil, fmt.Errorf("%w: delta base size mismatch", ErrInvalidObject)
+	}
+
+	dstSize, delta, err := deltaSize(delta)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, dstSize)
+
+	for len(delta) > 0 {
+		cmd := delta[0]
+		delta = delta[1:]
+
+		switch {
+		case cmd&deltaCopy != 0:
+			var offset, size int
+
+			offset, delta, err = deltaBytes(delta, cmd, 4) //nolint:mnd
+			if err != nil {
+				return nil, err
+			}
+
+			size, delta, err = deltaBytes(delta, cmd>>4, 3) //nolint:mnd
+			if err != nil {
+				return nil, err
+			}
+
+			if size == 0 {
+				size = deltaCopyMaxSize
+			}
+
+			if offset+size > len(base) {
+				return nil, fmt.Errorf("%w: delta copy out of range", ErrInvalidObject)
+			}
+
+			out = append(out, base[offset:offset+size]...)
+		case cmd != 0:
+			if int(cmd) > len(delta) {
+				return nil, fmt.Errorf("%w: truncated delta", ErrInvalidObject)
+			}
+
+			out = append(out, delta[:cmd]...)
+			delta = delta[cmd:]
+		default:
+			return nil, fmt.Errorf("%w: reserved delta instruction", ErrInvalidObject)
+		}
+	}
+
+	if len(out) != dstSize {
+		return nil, fmt.Errorf("%w: delta result size mismatch", ErrInvalidObject)
+	}
+
+	return out, nil
+}
+
+// deltaBytes reads a little-endian number of up to n bytes from the delta.
+// Only the bytes whose bit is set in the flags are present.
+func deltaBytes(delta []byte, flags byte, n int) (int, []byte, error) {
+	var value int
+
+	for i := range n {
+		if flags&(1<<i) == 0 {
+			continue
+		}
+
+		if len(delta) == 0 {
+			return 0, nil, fmt.Errorf("%w: truncated delta", ErrInvalidObject)
+		}
+
+		value |= int(delta[0]) << (byteShift * i)
+		delta = delta[1:]
+	}
+
+	return value, delta, nil
+}
+
+// deltaSize reads a little-endian base-128 size from the delta header.
+func deltaSize(delta []byte) (int, []byte, error) {
+	var size, shift int
+
+	for i, b := range delta {
+		size |= int(b&varintMask) << shift
+		shift += varintShift
+
+		if b&varintContinue == 0 {
+			return size, delta[i+1:], nil
+		}
+	}
+
+	return 0, nil, fmt.Errorf("%w: truncated delta header", ErrInvalidObject)
+}
@@ -0,0 +1,270 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+const (
+	sha1Size       = 20
+	uint32Size     = 4
+	fanoutEntries  = 256
+	idxHeaderSize  = 8
+	idxVersion     = 2
+	largeOffsetBit = 0x80000000
+	entryTypeShift = 4
+	entrySizeMask  = 0x0f
+
+	// maxDeltaDepth is the maximum length of a delta chain, the upper limit
+	// of the pack.depth option of git. Longer chains are only found in
+	// corrupt packs, e.g. with cyclic ref-deltas.
+	maxDeltaDepth = 4095
+)
+
+// Pack object types.
+const (
+	packCommit   = 1
+	packTree     = 2
+	packBlob     = 3
+	packTag      = 4
+	packOfsDelta = 6
+	packRefDelta = 7
+)
+
+//nolint:gochecknoglobals
+var idxMagic = []byte{0xff, 't', 'O', 'c'}
+
+// pack is a pack file with its version 2 index. The pack file is opened on
+// the first object lookup and kept open until the repository is closed.
+type pack struct {
+	path  string
+	count int
+	idx   []byte
+
+	once    sync.Once
+	file    *os.File
+	openErr error
+}
+
+func openPack(idxPath string) (*pack, error) {
+	idx, err := os.ReadFile(idxPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(idx) < idxHeaderSize+fanoutEntries*4 || !bytes.Equal(idx[:4], idxMagic) ||
+		binary.BigEndian.Uint32(idx[4:8]) != idxVersion {
+		return nil, fmt.Errorf("%w: unsupported pack index %s", ErrInvalidObject, idxPath)
+	}
+
+	count := int(binary.BigEndian.Uint32(idx[idxHeaderSize+(fanoutEntries-1)*4:]))
+
+	// Header, fanout table, names, checksums and offsets.
+	if len(idx) < idxHeaderSize+fanoutEntries*uint32Size+count*(sha1Size+2*uint32Size) {
+		return nil, fmt.Errorf("%w: truncated pack index %s", ErrInvalidObject, idxPath)
+	}
+
+	return &pack{
+		path:  strings.TrimSuffix(idxPath, ".idx") + ".pack",
+		count: count,
+		idx:   idx,
+	}, nil
+}
+
+// find returns the offset of the object in the pack file using a binary search
+// in the sorted object names of the index.
+func (p *pack) find(id []byte) (int64, bool, error) {
+	fanout := p.idx[idxHeaderSize:]
+	names := fanout[fanoutEntries*4:]
+
+	lo := 0
+	if id[0] > 0 {
+		lo = int(binary.BigEndian.Uint32(fanout[(int(id[0])-1)*4:]))
+	}
+
+	hi := int(binary.BigEndian.Uint32(fanout[int(id[0])*4:]))
+
+	for lo < hi {
+		mid := (lo + hi) / 2 //nolint:mnd
+
+		switch cmp := bytes.Compare(names[mid*sha1Size:(mid+1)*sha1Size], id); {
+		case cmp < 0:
+			lo = mid + 1
+		case cmp > 0:
+			hi = mid
+		default:
+			offset, err := p.offset(mid)
+
+			return offset, true, err
+		}
+	}
+
+	return 0, false, nil
+}
+
+func (p *pack) offset(n int) (int64, error) {
+	offsets := p.idx[idxHeaderSize+fanoutEntries*uint32Size+p.count*(sha1Size+uint32Size):]
+	offset := binary.BigEndian.Uint32(offsets[n*4:])
+
+	if offset&largeOffsetBit == 0 {
+		return int64(offset), nil
+	}
+
+	large := offsets[p.count*4:]
+	pos := int(offset&^largeOffsetBit) * 8 //nolint:mnd
+
+	if pos+8 > len(large) {
+		return 0, fmt.Errorf("%w: invalid large offset in %s", ErrInvalidObject, p.path)
+	}
+
+	return int64(binary.BigEndian.Uint64(large[pos:])), nil //nolint:gosec
+}
+
+// object reads the object at the given offset and resolves deltas. The depth
+// is the number of deltas resolved so far to read the object.
+func (p *pack) object(r *Repository, offset int64, depth int) (*Object, error) {
+	p.once.Do(func() {
+		p.file, p.openErr = os.Open(p.path)
+	})
+
+	if p.openErr != nil {
+		return nil, p.openErr
+	}
+
+	return p.readAt(r, offset, depth)
+}
+
+func (p *pack) close() error {
+	if p.file == nil {
+		return nil
+	}
+
+	return p.file.Close()
+}
+
+func (p *pack) readAt(r *Repository, offset int64, depth int) (*Object, error) {
+	if depth > maxDeltaDepth {
+		return nil, fmt.Errorf("%w: delta chain in %s exceeds %d objects", ErrInvalidObject, p.path, maxDeltaDepth)
+	}
+
+	br := bufio.NewReader(io.NewSectionReader(p.file, offset, 1<<62)) //nolint:mnd
+
+	typ, _, err := readEntryHeader(br)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s at %d: %w", ErrInvalidObject, p.path, offset, err)
+	}
+
+	var base *Object
+
+	switch typ {
+	case packOfsDelta:
+		rel, err := readDeltaOffset(br)
+		if err != nil || rel <= 0 || rel > offset {
+			return nil, fmt.Errorf("%w: invalid delta offset in %s", ErrInvalidObject, p.path)
+		}
+
+		if base, err = p.readAt(r, offset-rel, depth+1); err != nil {
+			return nil, err
+		}
+	case packRefDelta:
+		id := make([]byte, sha1Size)
+		if _, err := io.ReadFull(br, id); err != nil {
+			return nil, fmt.Errorf("%w: %s at %d: %w", ErrInvalidObject, p.path, offset, err)
+		}
+
+		if base, err = r.object(hex.EncodeToString(id), depth+1); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := inflate(br)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s at %d: %w", ErrInvalidObject, p.path, offset, err)
+	}
+
+	if base != nil {
+		data, err = applyDelta(base.Data, data)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Object{Type: base.Type, Data: data}, nil
+	}
+
+	objType, ok := packObjectTypes()[typ]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown pack object type %d", ErrInvalidObject, typ)
+	}
+
+	return &Object{Type: objType, Data: data}, nil
+}
+
+func packObjectTypes() map[int]ObjectType {
+	return map[int]ObjectType{
+		packCommit: ObjectCommit,
+		packTree:   ObjectTree,
+		packBlob:   ObjectBlob,
+		packTag:    ObjectTag,
+	}
+}
+
+// readEntryHeader reads the type and inflated size of a pack entry.
+func readEntryHeader(br *bufio.Reader) (int, int64, error) {
+	b, err := br.ReadByte()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	typ := int(b>>entryTypeShift) & 0x07 //nolint:mnd
+	size := int64(b & entrySizeMask)
+	shift := entryTypeShift
+
+	for b&varintContinue != 0 {
+		if b, err = br.ReadByte(); err != nil {
+			return 0, 0, err
+		}
+
+		size |= int64(b&varintMask) << shift
+		shift += varintShift
+	}
+
+	return typ, size, nil
+}
+
+// readDeltaOffset reads the negative offset of an offset delta base.
+func readDeltaOffset(br *bufio.Reader) (int64, error) {
+	b, err := br.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+
+	offset := int64(b & varintMask)
+
+	for b&varintContinue != 0 {
+		if b, err = br.ReadByte(); err != nil {
+			return 0, err
+		}
+
+		offset = ((offset + 1) << varintShift) | int64(b&varintMask)
+	}
+
+	return offset, nil
+}
+
+func inflate(r io.Reader) ([]byte, error) {
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	return io.ReadAll(zr)
+}
@@ -0,0 +1,102 @@
+package git
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thegeeklab/wp-plugin-go/v6/internal/gittest"
+)
+
+// writeRefDeltaPack writes a pack with a single ref-delta object whose base
+// is the object itself.
+func writeRefDeltaPack(t *testing.T, dir string, id []byte) {
+	t.Helper()
+
+	const packHeaderSize = 12
+
+	var delta bytes.Buffer
+
+	zw := zlib.NewWriter(&delta)
+	_, err := zw.Write([]byte{0x01, 0x01, 0x01, 'x'})
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	var packData bytes.Buffer
+
+	packData.WriteString("PACK")
+	_ = binary.Write(&packData, binary.BigEndian, []uint32{2, 1})
+	packData.WriteByte(packRefDelta<<entryTypeShift | 0x04)
+	packData.Write(id)
+	packData.Write(delta.Bytes())
+
+	var idx bytes.Buffer
+
+	idx.Write(idxMagic)
+	_ = binary.Write(&idx, binary.BigEndian, uint32(idxVersion))
+
+	for i := range fanoutEntries {
+		count := uint32(0)
+		if i >= int(id[0]) {
+			count = 1
+		}
+
+		_ = binary.Write(&idx, binary.BigEndian, count)
+	}
+
+	idx.Write(id)
+	_ = binary.Write(&idx, binary.BigEndian, []uint32{0, packHeaderSize})
+	idx.Write(make([]byte, 2*sha1Size))
+
+	packDir := filepath.Join(dir, ".git", "objects", "pack")
+	require.NoError(t, os.WriteFile(filepath.Join(packDir, "pack-cycle.pack"), packData.Bytes(), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(packDir, "pack-cycle.idx"), idx.Bytes(), 0o600))
+}
+
+func TestPackDeltaCycle(t *testing.T) {
+	dir := gittest.Init(t, "https://example.com/octocat/hello-world.git")
+	sha := strings.Repeat("ab", sha1Size)
+	id := bytes.Repeat([]byte{0xab}, sha1Size)
+
+	writeRefDeltaPack(t, dir, id)
+
+	repo, err := Open(dir)
+	require.NoError(t, err)
+
+	defer repo.Close()
+
+	_, err = repo.Object(sha)
+	assert.ErrorIs(t, err, ErrInvalidObject)
+	assert.ErrorContains(t, err, "delta chain")
+}
+
+func TestPackKeepsFileOpen(t *testing.T) {
+	dir := testRepo(t)
+	gittest.Run(t, dir, "gc", "--quiet")
+
+	repo, err := Open(dir)
+	require.NoError(t, err)
+
+	head, err := repo.Head()
+	require.NoError(t, err)
+
+	_, err = repo.Object(head.SHA)
+	require.NoError(t, err)
+	require.Len(t, repo.packs, 1)
+
+	file := repo.packs[0].file
+	require.NotNil(t, file)
+
+	_, err = repo.Object(head.SHA)
+	require.NoError(t, err)
+	assert.Same(t, file, repo.packs[0].file)
+
+	assert.NoError(t, repo.Close())
+	assert.Nil(t, repo.packs)
+}
@@ -0,0 +1,366 @@
+// Package git reads metadata of a local git repository directly from the .git
+// directory without requiring the git binary. Only SHA-1 repositories are
+// supported.
+package git
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	headsPrefix   = "refs/heads/"
+	tagsPrefix    = "refs/tags/"
+	symrefPrefix  = "ref: "
+	gitdirPrefix  = "gitdir: "
+	maxSymrefHops = 5
+)
+
+var (
+	ErrNotRepository = errors.New("not a git repository")
+	ErrRefNotFound   = errors.New("reference not found")
+)
+
+// Repository is a local git repository.
+type Repository struct {
+	// Path of the git directory.
+	GitDir string
+	// Path of the git directory shared by all worktrees.
+	CommonDir string
+
+	packs []*pack
+}
+
+// Head is the current HEAD of a repository.
+type Head struct {
+	// Full name of the checked out branch, empty if HEAD is detached.
+	Ref string
+	// SHA of the checked out commit.
+	SHA string
+}
+
+// Open opens the repository containing the given path. Parent directories are
+// searched for a .git directory or file.
+// The repository should be closed to release the pack files opened to read
+// objects.
+func Open(path string) (*Repository, error) {
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		gitDir, err := resolveGitDir(filepath.Join(dir, ".git"))
+		if err == nil {
+			return openGitDir(gitDir)
+		}
+
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, fmt.Errorf("%w: %s", ErrNotRepository, path)
+		}
+
+		dir = parent
+	}
+}
+
+// Close closes the pack files opened to read objects.
+func (r *Repository) Close() error {
+	errs := make([]error, 0, len(r.packs))
+
+	for _, p := range r.packs {
+		errs = append(errs, p.close())
+	}
+
+	r.packs = nil
+
+	return errors.Join(errs...)
+}
+
+// resolveGitDir returns the git directory for a .git path, which is either the
+// directory itself or a file pointing to it as used by worktrees and submodules.
+func resolveGitDir(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+
+	if info.IsDir() {
+		return path, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	target, ok := strings.CutPrefix(strings.TrimSpace(string(data)), gitdirPrefix)
+	if !ok {
+		return "", fmt.Errorf("%w: invalid .git file %s", ErrNotRepository, path)
+	}
+
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(path), target)
+	}
+
+	return target, nil
+}
+
+func openGitDir(gitDir string) (*Repository, error) {
+	if _, err := os.Stat(filepath.Join(gitDir, "HEAD")); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotRepository, gitDir)
+	}
+
+	repo := &Repository{
+		GitDir:    gitDir,
+		CommonDir: gitDir,
+	}
+
+	if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir := strings.TrimSpace(string(data))
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(gitDir, commonDir)
+		}
+
+		repo.CommonDir = filepath.Clean(commonDir)
+	}
+
+	return repo, nil
+}
+
+// Head returns the checked out branch and commit.
+func (r *Repository) Head() (Head, error) {
+	data, err := os.ReadFile(filepath.Join(r.GitDir, "HEAD"))
+	if err != nil {
+		return Head{}, err
+	}
+
+	value := strings.TrimSpace(string(data))
+
+	ref, ok := strings.CutPrefix(value, symrefPrefix)
+	if !ok {
+		return Head{SHA: value}, nil
+	}
+
+	sha, err := r.ResolveRef(ref)
+	if err != nil {
+		return Head{}, err
+	}
+
+	return Head{Ref: ref, SHA: sha}, nil
+}
+
+// Branch returns the short name of the checked out branch, empty if HEAD is
+// detached.
+func (h Head) Branch() string {
+	return strings.TrimPrefix(h.Ref, headsPrefix)
+}
+
+// ResolveRef returns the SHA the given full reference name points to. Symbolic
+// references are followed.
+func (r *Repository) ResolveRef(name string) (string, error) {
+	for range maxSymrefHops {
+		value, err := r.readRef(name)
+		if err != nil {
+			return "", err
+		}
+
+		target, ok := strings.CutPrefix(value, symrefPrefix)
+		if !ok {
+			return value, nil
+		}
+
+		name = target
+	}
+
+	return "", fmt.Errorf("%w: too many symbolic references for %s", ErrRefNotFound, name)
+}
+
+// readRef reads a loose reference and falls back to the packed references.
+func (r *Repository) readRef(name string) (string, error) {
+	for _, dir := range r.refDirs(name) {
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err == nil {
+			return strings.TrimSpace(string(data)), nil
+		}
+	}
+
+	refs, err := r.packedRefs()
+	if err != nil {
+		return "", err
+	}
+
+	if ref, ok := refs[name]; ok {
+		return ref.sha, nil
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrRefNotFound, name)
+}
+
+// refDirs returns the directories a reference is looked up in. Pseudo refs like
+// HEAD are per worktree, all other refs are shared.
+func (r *Repository) refDirs(name string) []string {
+	if strings.HasPrefix(name, "refs/") {
+		return []string{r.CommonDir}
+	}
+
+	return []string{r.GitDir, r.CommonDir}
+}
+
+type packedRef struct {
+	sha    string
+	peeled string
+}
+
+// packedRefs parses the packed-refs file. Peeled lines starting with ^ contain
+// the commit an annotated tag points to.
+func (r *Repository) packedRefs() (map[string]packedRef, error) {
+	refs := make(map[string]packedRef)
+
+	file, err := os.Open(filepath.Join(r.CommonDir, "packed-refs"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return refs, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	var last string
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case line == "", strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "^"):
+			if ref, ok := refs[last]; ok {
+				ref.peeled = line[1:]
+				refs[last] = ref
+			}
+		default:
+			sha, name, ok := strings.Cut(line, " ")
+			if !ok {
+				continue
+			}
+
+			refs[name] = packedRef{sha: sha}
+			last = name
+		}
+	}
+
+	return refs, scanner.Err()
+}
+
+// Refs returns all references with the given prefix, e.g. refs/tags/, mapped to
+// the SHA they point to.
+func (r *Repository) Refs(prefix string) (map[string]string, error) {
+	packed, err := r.packedRefs()
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make(map[string]string)
+
+	for name, ref := range packed {
+		if strings.HasPrefix(name, prefix) {
+			refs[name] = ref.sha
+		}
+	}
+
+	root := os.DirFS(r.CommonDir)
+
+	err = fs.WalkDir(root, "refs", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasPrefix(name, prefix) {
+			return err
+		}
+
+		data, err := fs.ReadFile(root, name)
+		if err != nil {
+			return err
+		}
+
+		refs[name] = strings.TrimSpace(string(data))
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	return refs, nil
+}
+
+// TagsAt returns the short names of all tags pointing to the given commit,
+// including annotated tags. The names are sorted.
+func (r *Repository) TagsAt(sha string) ([]string, error) {
+	refs, err := r.Refs(tagsPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	packed, err := r.packedRefs()
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make([]string, 0)
+
+	for name, target := range refs {
+		if target != sha {
+			if ref, ok := packed[name]; ok && ref.sha == target && ref.peeled != "" {
+				target = ref.peeled
+			} else {
+				target, err = r.peel(target)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		if target == sha {
+			tags = append(tags, strings.TrimPrefix(name, tagsPrefix))
+		}
+	}
+
+	slices.Sort(tags)
+
+	return tags, nil
+}
+
+// peel follows annotated tag objects to the object they point to.
+func (r *Repository) peel(sha string) (string, error) {
+	for range maxSymrefHops {
+		obj, err := r.Object(sha)
+		if err != nil {
+			return "", err
+		}
+
+		if obj.Type != ObjectTag {
+			return sha, nil
+		}
+
+		tag, err := parseTag(obj.Data)
+		if err != nil {
+			return "", err
+		}
+
+		sha = tag.Object
+	}
+
+	return sha, nil
+}
@@ -0,0 +1,225 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thegeeklab/wp-plugin-go/v6/internal/gittest"
+)
+
+// testRepo creates a repository with three commits, a lightweight and an
+// annotated tag on HEAD and an origin remote using the git binary.
+func testRepo(tb testing.TB) string {
+	tb.Helper()
+
+	dir := gittest.Init(tb, "git@example.com:octocat/hello-world.git")
+	content := strings.Repeat("line of content that is repeated to produce deltas\n", 200)
+
+	for i, message := range []string{"initial commit", "second commit", "feat: add feature\n\nlonger description"} {
+		content += strings.Repeat("x", i+1) + "\n"
+
+		require.NoError(tb, os.WriteFile(filepath.Join(dir, "file.txt"), []byte(content), 0o600))
+		gittest.Run(tb, dir, "add", "file.txt")
+		gittest.Run(tb, dir, "commit", "--quiet", "--message", message)
+	}
+
+	gittest.Run(tb, dir, "tag", "v1.0.0")
+	gittest.Run(tb, dir, "tag", "--annotate", "--message", "release", "v1.0.0-annotated")
+	gittest.Run(tb, dir, "tag", "old", "HEAD~1")
+
+	return dir
+}
+
+func TestRepository(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(tb testing.TB, dir string)
+	}{
+		{
+			name:  "loose objects",
+			setup: func(_ testing.TB, _ string) {},
+		},
+		{
+			name: "packed objects and refs",
+			setup: func(tb testing.TB, dir string) {
+				tb.Helper()
+				gittest.Run(tb, dir, "gc", "--quiet", "--aggressive")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := testRepo(t)
+			want := gittest.Run(t, dir, "rev-parse", "HEAD")
+			parent := gittest.Run(t, dir, "rev-parse", "HEAD~1")
+			tree := gittest.Run(t, dir, "rev-parse", "HEAD^{tree}")
+
+			tt.setup(t, dir)
+
+			sub := filepath.Join(dir, "sub")
+			require.NoError(t, os.Mkdir(sub, 0o700))
+
+			repo, err := Open(sub)
+			require.NoError(t, err)
+
+			defer repo.Close()
+
+			head, err := repo.Head()
+			require.NoError(t, err)
+			assert.Equal(t, Head{Ref: "refs/heads/main", SHA: want}, head)
+			assert.Equal(t, "main", head.Branch())
+
+			commit, err := repo.Commit(head.SHA)
+			require.NoError(t, err)
+			assert.Equal(t, tree, commit.Tree)
+			assert.Equal(t, []string{parent}, commit.Parents)
+			assert.Equal(t, "feat: add feature\n\nlonger description\n", commit.Message)
+			assert.Equal(t, "Octo Cat", commit.Author.Name)
+			assert.Equal(t, "octocat@example.com", commit.Author.Email)
+			assert.True(t, time.Unix(1700000000, 0).Equal(commit.Author.When))
+
+			_, offset := commit.Author.When.Zone()
+			assert.Equal(t, 2*60*60, offset)
+
+			tags, err := repo.TagsAt(head.SHA)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"v1.0.0", "v1.0.0-annotated"}, tags)
+
+			tags, err = repo.TagsAt(parent)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"old"}, tags)
+
+			blob, err := repo.Object(gittest.Run(t, dir, "rev-parse", "HEAD:file.txt"))
+			require.NoError(t, err)
+			assert.Equal(t, ObjectBlob, blob.Type)
+			assert.True(t, strings.HasSuffix(string(blob.Data), "\nxxx\n"))
+
+			// Older versions of a file are stored as delta in pack files.
+			blob, err = repo.Object(gittest.Run(t, dir, "rev-parse", "HEAD~2:file.txt"))
+			require.NoError(t, err)
+			assert.Equal(t, gittest.Run(t, dir, "show", "HEAD~2:file.txt"), strings.TrimSpace(string(blob.Data)))
+
+			remote, err := repo.Remote()
+			require.NoError(t, err)
+			assert.Equal(t, Remote{Name: "origin", URL: "git@example.com:octocat/hello-world.git"}, remote)
+		})
+	}
+}
+
+func TestRepositoryDetachedHead(t *testing.T) {
+	dir := testRepo(t)
+	want := gittest.Run(t, dir, "rev-parse", "HEAD~1")
+
+	gittest.Run(t, dir, "checkout", "--quiet", "--detach", "HEAD~1")
+
+	repo, err := Open(dir)
+	require.NoError(t, err)
+
+	defer repo.Close()
+
+	head, err := repo.Head()
+	require.NoError(t, err)
+	assert.Equal(t, Head{SHA: want}, head)
+	assert.Empty(t, head.Branch())
+}
+
+func TestRepositoryWorktree(t *testing.T) {
+	dir := testRepo(t)
+	worktree := filepath.Join(t.TempDir(), "worktree")
+
+	gittest.Run(t, dir, "worktree", "add", "--quiet", "-b", "feature", worktree, "HEAD~1")
+
+	repo, err := Open(worktree)
+	require.NoError(t, err)
+
+	defer repo.Close()
+
+	head, err := repo.Head()
+	require.NoError(t, err)
+	assert.Equal(t, "feature", head.Branch())
+	assert.Equal(t, gittest.Run(t, dir, "rev-parse", "HEAD~1"), head.SHA)
+
+	remote, err := repo.Remote()
+	require.NoError(t, err)
+	assert.Equal(t, "origin", remote.Name)
+}
+
+func TestOpenNotRepository(t *testing.T) {
+	_, err := Open(t.TempDir())
+	assert.ErrorIs(t, err, ErrNotRepository)
+}
+
+func TestParseRemoteURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		want    RemoteInfo
+		webURL  string
+		wantErr bool
+	}{
+		{
+			name:   "https",
+			url:    "https://example.com/octocat/hello-world.git",
+			want:   RemoteInfo{Host: "example.com", Owner: "octocat", Name: "hello-world"},
+			webURL: "https://example.com/octocat/hello-world",
+		},
+		{
+			name:   "https with user and port",
+			url:    "https://user@example.com:8443/octocat/hello-world",
+			want:   RemoteInfo{Host: "example.com", Owner: "octocat", Name: "hello-world"},
+			webURL: "https://example.com/octocat/hello-world",
+		},
+		{
+			name:   "scp-like",
+			url:    "git@example.com:octocat/hello-world.git",
+			want:   RemoteInfo{Host: "example.com", Owner: "octocat", Name: "hello-world"},
+			webURL: "https://example.com/octocat/hello-world",
+		},
+		{
+			name:   "ssh with nested groups",
+			url:    "ssh://git@example.com:2222/group/sub/hello-world.git",
+			want:   RemoteInfo{Host: "example.com", Owner: "group/sub", Name: "hello-world"},
+			webURL: "https://example.com/group/sub/hello-world",
+		},
+		{
+			name:    "invalid",
+			url:     "hello-world",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRemoteURL(tt.url)
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.webURL, got.WebURL())
+		})
+	}
+}
+
+func TestApplyDelta(t *testing.T) {
+	base := []byte("hello world")
+	// Source size 11, target size 12, copy 6 bytes from offset 0, insert
+	// "there", copy the last byte of the base.
+	delta := []byte{11, 12, 0x90, 6, 5, 't', 'h', 'e', 'r', 'e', 0x91, 10, 1}
+
+	got, err := applyDelta(base, delta)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello thered", string(got))
+
+	_, err = applyDelta([]byte("short"), delta)
+	assert.ErrorIs(t, err, ErrInvalidObject)
+}
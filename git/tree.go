@@ -0,0 +1,166 @@
+package git
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"slices"
+)
+
+const modeDir = "40000"
+
+// TreeEntry is an entry of a tree object.
+type TreeEntry struct {
+	Mode string
+	Name string
+	SHA  string
+}
+
+// IsDir reports whether the entry is a subtree.
+func (e TreeEntry) IsDir() bool {
+	return e.Mode == modeDir
+}
+
+// Tree reads the entries of the tree object with the given SHA.
+func (r *Repository) Tree(sha string) ([]TreeEntry, error) {
+	obj, err := r.Object(sha)
+	if err != nil {
+		return nil, err
+	}
+
+	if obj.Type != ObjectTree {
+		return nil, fmt.Errorf("%w: %s is a %s, not a tree", ErrInvalidObject, sha, obj.Type)
+	}
+
+	return parseTree(obj.Data)
+}
+
+// parseTree parses the binary tree format. Each entry consists of the mode and
+// the name separated by a space, a null byte and the raw SHA.
+func parseTree(data []byte) ([]TreeEntry, error) {
+	entries := make([]TreeEntry, 0)
+
+	for len(data) > 0 {
+		header, rest, ok := bytes.Cut(data, []byte{0})
+		if !ok || len(rest) < sha1Size {
+			return nil, fmt.Errorf("%w: truncated tree entry", ErrInvalidObject)
+		}
+
+		mode, name, ok := bytes.Cut(header, []byte{' '})
+		if !ok {
+			return nil, fmt.Errorf("%w: invalid tree entry", ErrInvalidObject)
+		}
+
+		entries = append(entries, TreeEntry{
+			Mode: string(mode),
+			Name: string(name),
+			SHA:  hex.EncodeToString(rest[:sha1Size]),
+		})
+
+		data = rest[sha1Size:]
+	}
+
+	return entries, nil
+}
+
+// ChangedFiles returns the sorted paths of all files that were added, modified
+// or deleted between the two commits.
+func (r *Repository) ChangedFiles(from, to string) ([]string, error) {
+	fromCommit, err := r.Commit(from)
+	if err != nil {
+		return nil, err
+	}
+
+	toCommit, err := r.Commit(to)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]string, 0)
+
+	if err := r.diffTrees("", fromCommit.Tree, toCommit.Tree, &files); err != nil {
+		return nil, err
+	}
+
+	slices.Sort(files)
+
+	return files, nil
+}
+
+// diffTrees collects the changed files of two trees recursively. An empty SHA
+// stands for a tree that does not exist on one side.
+func (r *Repository) diffTrees(dir, from, to string, files *[]string) error {
+	if from == to {
+		return nil
+	}
+
+	fromEntries, err := r.treeEntries(from)
+	if err != nil {
+		return err
+	}
+
+	toEntries, err := r.treeEntries(to)
+	if err != nil {
+		return err
+	}
+
+	for name, entry := range toEntries {
+		if err := r.diffEntry(path.Join(dir, name), fromEntries[name], entry, files); err != nil {
+			return err
+		}
+	}
+
+	for name, entry := range fromEntries {
+		if _, ok := toEntries[name]; !ok {
+			if err := r.diffEntry(path.Join(dir, name), entry, TreeEntry{}, files); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// diffEntry compares two entries of the same path. If the path changed between
+// a file and a directory, both sides are reported.
+func (r *Repository) diffEntry(name string, from, to TreeEntry, files *[]string) error {
+	if from == to {
+		return nil
+	}
+
+	fromTree, toTree := "", ""
+
+	if from.IsDir() {
+		fromTree = from.SHA
+	}
+
+	if to.IsDir() {
+		toTree = to.SHA
+	}
+
+	if from.SHA != "" && !from.IsDir() || to.SHA != "" && !to.IsDir() {
+		*files = append(*files, name)
+	}
+
+	return r.diffTrees(name, fromTree, toTree, files)
+}
+
+func (r *Repository) treeEntries(sha string) (map[string]TreeEntry, error) {
+	entries := make(map[string]TreeEntry)
+
+	if sha == "" {
+		return entries, nil
+	}
+
+	tree, err := r.Tree(sha)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range tree {
+		entries[entry.Name] = entry
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,79 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thegeeklab/wp-plugin-go/v6/internal/gittest"
+)
+
+func TestRepositoryChangedFiles(t *testing.T) {
+	dir := testRepo(t)
+	from := gittest.Run(t, dir, "rev-parse", "HEAD")
+
+	write := func(name, content string) {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	write("docs/guide/index.md", "guide\n")
+	write("docs/unchanged.md", "unchanged\n")
+	write("swap", "file\n")
+	gittest.Run(t, dir, "add", "--all")
+	gittest.Run(t, dir, "commit", "--quiet", "--message", "add docs")
+
+	base := gittest.Run(t, dir, "rev-parse", "HEAD")
+
+	write("file.txt", "modified\n")
+	write("docs/guide/index.md", "changed guide\n")
+	write("docs/new.md", "new\n")
+	require.NoError(t, os.Remove(filepath.Join(dir, "swap")))
+	write("swap/nested.txt", "dir\n")
+	gittest.Run(t, dir, "add", "--all")
+	gittest.Run(t, dir, "commit", "--quiet", "--message", "change docs")
+	gittest.Run(t, dir, "gc", "--quiet")
+
+	to := gittest.Run(t, dir, "rev-parse", "HEAD")
+
+	repo, err := Open(dir)
+	require.NoError(t, err)
+
+	defer repo.Close()
+
+	for _, tt := range []struct{ from, to string }{{from, to}, {base, to}, {to, base}, {to, to}} {
+		got, err := repo.ChangedFiles(tt.from, tt.to)
+		require.NoError(t, err)
+
+		want := make([]string, 0)
+
+		if out := gittest.Run(t, dir, "diff", "--name-only", tt.from, tt.to); out != "" {
+			want = strings.Split(out, "\n")
+		}
+
+		assert.Equal(t, want, got)
+	}
+
+	got, err := repo.ChangedFiles(base, to)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"docs/guide/index.md", "docs/new.md", "file.txt", "swap", "swap/nested.txt"}, got)
+}
+
+func TestParseTree(t *testing.T) {
+	sha := strings.Repeat("ab", sha1Size)
+	id := strings.Repeat("\xab", sha1Size)
+	raw := []byte("100644 file.txt\x00" + id + "40000 dir\x00" + id)
+
+	got, err := parseTree(raw)
+	require.NoError(t, err)
+	assert.Equal(t, []TreeEntry{{Mode: "100644", Name: "file.txt", SHA: sha}, {Mode: "40000", Name: "dir", SHA: sha}}, got)
+	assert.True(t, got[1].IsDir())
+
+	_, err = parseTree(raw[:len(raw)-1])
+	assert.ErrorIs(t, err, ErrInvalidObject)
+}
@@ -0,0 +1,34 @@
+// Package execstub holds the test hook that replaces the execution of commands
+// of the exec package. It is internal to be used by the plugintest harness
+// only, plugins cannot install a stub in production code.
+package execstub
+
+import (
+	"os/exec"
+	"sync/atomic"
+)
+
+// Func is called instead of executing a command while a stub is installed.
+type Func func(cmd *exec.Cmd) error
+
+//nolint:gochecknoglobals
+var stub atomic.Pointer[Func]
+
+// Set replaces the execution of all commands by the given function until the
+// returned restore function is called.
+func Set(fn Func) func() {
+	prev := stub.Swap(&fn)
+
+	return func() {
+		stub.Store(prev)
+	}
+}
+
+// Load returns the installed stub or nil.
+func Load() Func {
+	if fn := stub.Load(); fn != nil {
+		return *fn
+	}
+
+	return nil
+}
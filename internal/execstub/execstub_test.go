@@ -0,0 +1,26 @@
+package execstub
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSet(t *testing.T) {
+	assert.Nil(t, Load())
+
+	restore := Set(func(_ *exec.Cmd) error { return nil })
+
+	assert.NotNil(t, Load())
+
+	restoreNested := Set(func(_ *exec.Cmd) error { return exec.ErrNotFound })
+
+	assert.ErrorIs(t, Load()(nil), exec.ErrNotFound)
+
+	restoreNested()
+	assert.NoError(t, Load()(nil))
+
+	restore()
+	assert.Nil(t, Load())
+}
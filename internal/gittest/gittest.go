@@ -0,0 +1,55 @@
+// Package gittest provides helpers to create git repositories with the git
+// binary in tests.
+package gittest
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Init creates an empty repository on the `main` branch with the given origin
+// remote in a temporary directory. The test is skipped if the git binary is
+// not available.
+func Init(tb testing.TB, remote string) string {
+	tb.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		tb.Skip("git binary not found")
+	}
+
+	dir := tb.TempDir()
+
+	Run(tb, dir, "init", "--quiet", "--initial-branch", "main")
+	Run(tb, dir, "remote", "add", "origin", remote)
+
+	return dir
+}
+
+// Run runs git in the directory and returns the trimmed output. The author,
+// committer and dates are fixed and the system and global config is ignored.
+func Run(tb testing.TB, dir string, args ...string) string {
+	tb.Helper()
+
+	cmd := exec.CommandContext(tb.Context(), "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(
+		os.Environ(),
+		"GIT_AUTHOR_NAME=Octo Cat",
+		"GIT_AUTHOR_EMAIL=octocat@example.com",
+		"GIT_AUTHOR_DATE=1700000000 +0200",
+		"GIT_COMMITTER_NAME=Octo Cat",
+		"GIT_COMMITTER_EMAIL=octocat@example.com",
+		"GIT_COMMITTER_DATE=1700000000 +0200",
+		"GIT_CONFIG_NOSYSTEM=1",
+		"HOME="+dir,
+	)
+
+	out, err := cmd.CombinedOutput()
+	require.NoError(tb, err, string(out))
+
+	return strings.TrimSpace(string(out))
+}
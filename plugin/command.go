@@ -0,0 +1,66 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/urfave/cli/v3"
+)
+
+// CommandEnv is the environment variable to select the plugin command if none
+// is passed as argument.
+const CommandEnv = "PLUGIN_COMMAND"
+
+var ErrUnknownCommand = errors.New("unknown command")
+
+// Command defines a subcommand of the plugin. The lifecycle functions of the
+// plugin options apply to all commands.
+type Command struct {
+	// Name of the command.
+	Name string
+	// Description of the command.
+	Description string
+	// Flags of the command.
+	Flags []cli.Flag
+	// Execute function of the command.
+	Execute ExecuteFunc
+}
+
+// commands creates the cli commands for the given plugin commands.
+func (p *Plugin) commands(cmds []Command) []*cli.Command {
+	result := make([]*cli.Command, 0, len(cmds))
+
+	for _, c := range cmds {
+		if p.config != nil {
+			addConfigSource(p.config, c.Flags)
+		}
+
+		result = append(result, &cli.Command{
+			Name:   c.Name,
+			Usage:  c.Description,
+			Flags:  c.Flags,
+			Action: p.action(c.Execute),
+		})
+	}
+
+	return result
+}
+
+// defaultAction returns the action of the root command if the plugin has
+// commands. It runs the plugin execute function if set, otherwise it fails.
+func (p *Plugin) defaultAction(execute ExecuteFunc) cli.ActionFunc {
+	if execute != nil {
+		return p.action(execute)
+	}
+
+	return func(_ context.Context, cmd *cli.Command) error {
+		err := fmt.Errorf("%w: %q", ErrUnknownCommand, cmd.DefaultCommand)
+		if cmd.DefaultCommand == "" {
+			err = fmt.Errorf("%w: no command given", ErrUnknownCommand)
+		}
+
+		return NewError(CategoryUsage, err).
+			WithHint("pass one of the commands as argument or set %s", CommandEnv)
+	}
+}
@@ -0,0 +1,104 @@
+package plugin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v3"
+)
+
+func TestCommands(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		envCommand string
+		want       string
+		wantErr    error
+	}{
+		{
+			name: "command from argument",
+			args: []string{"dummy", "publish", "--target", "prod"},
+			want: "publish:prod",
+		},
+		{
+			name:       "command from environment",
+			envCommand: "build",
+			args:       []string{"dummy"},
+			want:       "build",
+		},
+		{
+			name:       "argument overrides environment",
+			envCommand: "build",
+			args:       []string{"dummy", "publish"},
+			want:       "publish:",
+		},
+		{
+			name:    "no command",
+			args:    []string{"dummy"},
+			wantErr: ErrUnknownCommand,
+		},
+		{
+			name:       "unknown command",
+			envCommand: "invalid",
+			args:       []string{"dummy"},
+			wantErr:    ErrUnknownCommand,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(CommandEnv, tt.envCommand)
+			t.Setenv("CI_REPO", "octocat/hello-world")
+
+			var (
+				got  string
+				repo string
+			)
+
+			var p *Plugin
+
+			p = New(Options{
+				Name: "dummy",
+				Commands: []Command{
+					{
+						Name: "build",
+						Execute: func(_ context.Context) error {
+							got = "build"
+							repo = p.Metadata.Repository.Slug
+
+							return nil
+						},
+					},
+					{
+						Name: "publish",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:    "target",
+								Sources: cli.EnvVars("PLUGIN_TARGET"),
+							},
+						},
+						Execute: func(_ context.Context) error {
+							got = "publish:" + p.App.Command("publish").String("target")
+							repo = p.Metadata.Repository.Slug
+
+							return nil
+						},
+					},
+				},
+			})
+
+			err := p.App.Run(t.Context(), tt.args)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Equal(t, CategoryUsage, AsError(err).Category)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, "octocat/hello-world", repo)
+		})
+	}
+}
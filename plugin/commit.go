@@ -23,213 +23,191 @@ import (
 type (
 	// Commit defines runtime metadata for a commit.
 	Commit struct {
-		URL          string
-		SHA          string
-		Ref          string
-		Refspec      string
-		PullRequest  int64
-		SourceBranch string
-		TargetBranch string
-		Branch       string
-		Tag          string
-		Message      string
-		Title        string
-		Description  string
-		Author       Author
+		URL                  string   `json:"url"`
+		SHA                  string   `json:"sha"`
+		Ref                  string   `json:"ref"`
+		Refspec              string   `json:"refspec"`
+		PullRequest          int64    `json:"pull_request"`
+		PullRequestLabels    []string `json:"pull_request_labels"`
+		PullRequestMilestone string   `json:"pull_request_milestone"`
+		PullRequestFromFork  bool     `json:"pull_request_from_fork"`
+		SourceBranch         string   `json:"source_branch"`
+		TargetBranch         string   `json:"target_branch"`
+		Branch               string   `json:"branch"`
+		Tag                  string   `json:"tag"`
+		Prerelease           bool     `json:"prerelease"`
+		Message              string   `json:"message"`
+		Title                string   `json:"title"`
+		Description          string   `json:"description"`
+		// Conventional Commit header of the message, empty if the title does not
+		// follow the specification.
+		Conventional ConventionalCommit `json:"conventional"`
+		// Git trailers of the message.
+		Trailers []Trailer `json:"trailers"`
+		// Whether the message contains a skip directive like [skip ci].
+		SkipCI bool   `json:"skip_ci"`
+		Author Author `json:"author"`
 	}
 
 	// Author defines runtime metadata for a commit author.
 	Author struct {
-		Name   string
-		Email  string
-		Avatar string
+		Name   string `json:"name"`
+		Email  string `json:"email"`
+		Avatar string `json:"avatar"`
 	}
 )
 
 func currFlags(category string) []cli.Flag {
+	return commitFlags(category, "", "CI_", "commit")
+}
+
+func currFromContext(c *cli.Command) Commit {
+	return commitFromContext(c, "")
+}
+
+func prevFlags(category string) []cli.Flag {
+	return commitFlags(category, "prev.", "CI_PREV_", "previous commit")
+}
+
+func prevFromContext(c *cli.Command) Commit {
+	return commitFromContext(c, "prev.")
+}
+
+// commitFlags returns the flags of a commit. The flag names and environment
+// variables are prefixed to distinguish the current and the previous commit.
+func commitFlags(category, prefix, envPrefix, usage string) []cli.Flag {
 	return []cli.Flag{
 		&cli.StringFlag{
-			Name:     "commit.url",
-			Usage:    "commit URL",
-			Sources:  cli.EnvVars("CI_COMMIT_URL"),
+			Name:     prefix + "commit.url",
+			Usage:    usage + " URL",
+			Sources:  cli.EnvVars(envPrefix + "COMMIT_URL"),
 			Category: category,
 		},
 		&cli.StringFlag{
-			Name:     "commit.sha",
-			Usage:    "commit SHA",
-			Sources:  cli.EnvVars("CI_COMMIT_SHA"),
+			Name:     prefix + "commit.sha",
+			Usage:    usage + " SHA",
+			Sources:  cli.EnvVars(envPrefix + "COMMIT_SHA"),
 			Category: category,
 		},
 		&cli.StringFlag{
-			Name:     "commit.ref",
-			Usage:    "commit ref",
-			Sources:  cli.EnvVars("CI_COMMIT_REF"),
+			Name:     prefix + "commit.ref",
+			Usage:    usage + " ref",
+			Sources:  cli.EnvVars(envPrefix + "COMMIT_REF"),
 			Category: category,
 		},
 		&cli.StringFlag{
-			Name:     "commit.refspec",
-			Usage:    "commit refspec",
-			Sources:  cli.EnvVars("CI_COMMIT_REFSPEC"),
+			Name:     prefix + "commit.refspec",
+			Usage:    usage + " refspec",
+			Sources:  cli.EnvVars(envPrefix + "COMMIT_REFSPEC"),
 			Category: category,
 		},
 		&cli.Int64Flag{
-			Name:     "commit.pull-request",
-			Usage:    "commit pull request",
-			Sources:  cli.EnvVars("CI_COMMIT_PULL_REQUEST"),
+			Name:     prefix + "commit.pull-request",
+			Usage:    usage + " pull request",
+			Sources:  cli.EnvVars(envPrefix + "COMMIT_PULL_REQUEST"),
 			Category: category,
 		},
-		&cli.StringFlag{
-			Name:     "commit.source-branch",
-			Usage:    "commit source branch",
-			Sources:  cli.EnvVars("CI_COMMIT_SOURCE_BRANCH"),
+		&cli.StringSliceFlag{
+			Name:     prefix + "commit.pull-request.labels",
+			Usage:    usage + " pull request labels",
+			Sources:  cli.EnvVars(envPrefix + "COMMIT_PULL_REQUEST_LABELS"),
 			Category: category,
 		},
 		&cli.StringFlag{
-			Name:     "commit.target-branch",
-			Usage:    "commit target branch",
-			Sources:  cli.EnvVars("CI_COMMIT_TARGET_BRANCH"),
+			Name:     prefix + "commit.pull-request.milestone",
+			Usage:    usage + " pull request milestone",
+			Sources:  cli.EnvVars(envPrefix + "COMMIT_PULL_REQUEST_MILESTONE"),
 			Category: category,
 		},
-		&cli.StringFlag{
-			Name:     "commit.branch",
-			Usage:    "commit branch",
-			Sources:  cli.EnvVars("CI_COMMIT_BRANCH"),
+		&cli.BoolFlag{
+			Name:     prefix + "commit.pull-request.from-fork",
+			Usage:    usage + " pull request is from a fork",
+			Sources:  cli.EnvVars(envPrefix + "COMMIT_PULL_REQUEST_FROM_FORK"),
 			Category: category,
 		},
 		&cli.StringFlag{
-			Name:     "commit.tag",
-			Usage:    "commit tag",
-			Sources:  cli.EnvVars("CI_COMMIT_TAG"),
+			Name:     prefix + "commit.source-branch",
+			Usage:    usage + " source branch",
+			Sources:  cli.EnvVars(envPrefix + "COMMIT_SOURCE_BRANCH"),
 			Category: category,
 		},
 		&cli.StringFlag{
-			Name:     "commit.message",
-			Usage:    "commit message",
-			Sources:  cli.EnvVars("CI_COMMIT_MESSAGE"),
+			Name:     prefix + "commit.target-branch",
+			Usage:    usage + " target branch",
+			Sources:  cli.EnvVars(envPrefix + "COMMIT_TARGET_BRANCH"),
 			Category: category,
 		},
 		&cli.StringFlag{
-			Name:     "commit.author.name",
-			Usage:    "commit author name",
-			Sources:  cli.EnvVars("CI_COMMIT_AUTHOR"),
+			Name:     prefix + "commit.branch",
+			Usage:    usage + " branch",
+			Sources:  cli.EnvVars(envPrefix + "COMMIT_BRANCH"),
 			Category: category,
 		},
 		&cli.StringFlag{
-			Name:     "commit.author.email",
-			Usage:    "commit author email",
-			Sources:  cli.EnvVars("CI_COMMIT_AUTHOR_EMAIL"),
+			Name:     prefix + "commit.tag",
+			Usage:    usage + " tag",
+			Sources:  cli.EnvVars(envPrefix + "COMMIT_TAG"),
 			Category: category,
 		},
-		&cli.StringFlag{
-			Name:     "commit.author.avatar",
-			Usage:    "commit author avatar",
-			Sources:  cli.EnvVars("CI_COMMIT_AUTHOR_AVATAR"),
-			Category: category,
-		},
-	}
-}
-
-func currFromContext(c *cli.Command) Commit {
-	commitTitle, commitDesc := splitMessage(c.String("commit.message"))
-
-	return Commit{
-		URL:          c.String("commit.url"),
-		SHA:          c.String("commit.sha"),
-		Ref:          c.String("commit.ref"),
-		Refspec:      c.String("commit.refspec"),
-		PullRequest:  c.Int64("commit.pull-request"),
-		SourceBranch: c.String("commit.source-branch"),
-		TargetBranch: c.String("commit.target-branch"),
-		Branch:       c.String("commit.branch"),
-		Tag:          c.String("commit.tag"),
-		Message:      c.String("commit.message"),
-		Title:        commitTitle,
-		Description:  commitDesc,
-		Author: Author{
-			Name:   c.String("commit.author.name"),
-			Email:  c.String("commit.author.email"),
-			Avatar: c.String("commit.author.avatar"),
-		},
-	}
-}
-
-func prevFlags(category string) []cli.Flag {
-	return []cli.Flag{
-		&cli.StringFlag{
-			Name:     "prev.commit.url",
-			Usage:    "previous commit URL",
-			Sources:  cli.EnvVars("CI_PREV_COMMIT_URL"),
-			Category: category,
-		},
-		&cli.StringFlag{
-			Name:     "prev.commit.sha",
-			Usage:    "previous commit SHA",
-			Sources:  cli.EnvVars("CI_PREV_COMMIT_SHA"),
-			Category: category,
-		},
-		&cli.StringFlag{
-			Name:     "prev.commit.ref",
-			Usage:    "previous commit ref",
-			Sources:  cli.EnvVars("CI_PREV_COMMIT_REF"),
-			Category: category,
-		},
-		&cli.StringFlag{
-			Name:     "prev.commit.refspec",
-			Usage:    "previous commit refspec",
-			Sources:  cli.EnvVars("CI_PREV_COMMIT_REFSPEC"),
+		&cli.BoolFlag{
+			Name:     prefix + "commit.prerelease",
+			Usage:    usage + " is a prerelease",
+			Sources:  cli.EnvVars(envPrefix + "COMMIT_PRERELEASE"),
 			Category: category,
 		},
 		&cli.StringFlag{
-			Name:     "prev.commit.branch",
-			Usage:    "previous commit branch",
-			Sources:  cli.EnvVars("CI_PREV_COMMIT_BRANCH"),
+			Name:     prefix + "commit.message",
+			Usage:    usage + " message",
+			Sources:  cli.EnvVars(envPrefix + "COMMIT_MESSAGE"),
 			Category: category,
 		},
 		&cli.StringFlag{
-			Name:     "prev.commit.message",
-			Usage:    "previous commit message",
-			Sources:  cli.EnvVars("CI_PREV_COMMIT_MESSAGE"),
+			Name:     prefix + "commit.author.name",
+			Usage:    usage + " author name",
+			Sources:  cli.EnvVars(envPrefix + "COMMIT_AUTHOR"),
 			Category: category,
 		},
 		&cli.StringFlag{
-			Name:     "prev.commit.author.name",
-			Usage:    "previous commit author name",
-			Sources:  cli.EnvVars("CI_PREV_COMMIT_AUTHOR"),
+			Name:     prefix + "commit.author.email",
+			Usage:    usage + " author email",
+			Sources:  cli.EnvVars(envPrefix + "COMMIT_AUTHOR_EMAIL"),
 			Category: category,
 		},
 		&cli.StringFlag{
-			Name:     "prev.commit.author.email",
-			Usage:    "previous commit author email",
-			Sources:  cli.EnvVars("CI_PREV_COMMIT_AUTHOR_EMAIL"),
-			Category: category,
-		},
-		&cli.StringFlag{
-			Name:     "prev.commit.author.avatar",
-			Usage:    "previous commit author avatar",
-			Sources:  cli.EnvVars("CI_PREV_COMMIT_AUTHOR_AVATAR"),
+			Name:     prefix + "commit.author.avatar",
+			Usage:    usage + " author avatar",
+			Sources:  cli.EnvVars(envPrefix + "COMMIT_AUTHOR_AVATAR"),
 			Category: category,
 		},
 	}
 }
 
-func prevFromContext(c *cli.Command) Commit {
-	commitTitle, commitDesc := splitMessage(c.String("commit.message"))
-
-	return Commit{
-		URL:         c.String("prev.commit.url"),
-		SHA:         c.String("prev.commit.sha"),
-		Ref:         c.String("prev.commit.ref"),
-		Refspec:     c.String("prev.commit.refspec"),
-		Branch:      c.String("prev.commit.branch"),
-		Message:     c.String("prev.commit.message"),
-		Title:       commitTitle,
-		Description: commitDesc,
+// commitFromContext creates a Commit from the flags with the given prefix.
+func commitFromContext(c *cli.Command, prefix string) Commit {
+	commit := Commit{
+		URL:                  c.String(prefix + "commit.url"),
+		SHA:                  c.String(prefix + "commit.sha"),
+		Ref:                  c.String(prefix + "commit.ref"),
+		Refspec:              c.String(prefix + "commit.refspec"),
+		PullRequest:          c.Int64(prefix + "commit.pull-request"),
+		PullRequestLabels:    c.StringSlice(prefix + "commit.pull-request.labels"),
+		PullRequestMilestone: c.String(prefix + "commit.pull-request.milestone"),
+		PullRequestFromFork:  c.Bool(prefix + "commit.pull-request.from-fork"),
+		SourceBranch:         c.String(prefix + "commit.source-branch"),
+		TargetBranch:         c.String(prefix + "commit.target-branch"),
+		Branch:               c.String(prefix + "commit.branch"),
+		Tag:                  c.String(prefix + "commit.tag"),
+		Prerelease:           c.Bool(prefix + "commit.prerelease"),
 		Author: Author{
-			Name:   c.String("prev.commit.author.name"),
-			Email:  c.String("prev.commit.author.email"),
-			Avatar: c.String("prev.commit.author.avatar"),
+			Name:   c.String(prefix + "commit.author.name"),
+			Email:  c.String(prefix + "commit.author.email"),
+			Avatar: c.String(prefix + "commit.author.avatar"),
 		},
 	}
+
+	commit.setMessage(c.String(prefix + "commit.message"))
+
+	return commit
 }
 
 // splitMessage splits a commit message into a title and description.
@@ -0,0 +1,271 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"github.com/urfave/cli/v3"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileEnv is the environment variable that points to the optional plugin
+// config file.
+const ConfigFileEnv = "PLUGIN_CONFIG_FILE"
+
+var (
+	ErrUnsupportedConfigFormat = errors.New("unsupported config file format")
+	ErrInvalidConfigValue      = errors.New("invalid config file value")
+)
+
+// configFile holds the lazily loaded content of a plugin config file.
+type configFile struct {
+	path   string
+	once   sync.Once
+	values map[string]any
+	err    error
+
+	// Errors of values that could not be converted to flag values, reported
+	// by check as flags are parsed before the plugin is set up.
+	mu         sync.Mutex
+	lookupErrs []error
+}
+
+// configFileSource is a cli.ValueSource that looks up a flag value in the
+// plugin config file.
+type configFileSource struct {
+	file *configFile
+	keys []string
+}
+
+func newConfigFile(path string) *configFile {
+	return &configFile{path: path}
+}
+
+// load reads and decodes the config file. The format is determined by the file
+// extension, supported are YAML, JSON and TOML.
+func (c *configFile) load() error {
+	c.once.Do(func() {
+		data, err := os.ReadFile(c.path)
+		if err != nil {
+			c.err = fmt.Errorf("failed to read config file: %w", err)
+
+			return
+		}
+
+		c.values = make(map[string]any)
+
+		switch ext := strings.ToLower(filepath.Ext(c.path)); ext {
+		case ".yaml", ".yml":
+			err = yaml.Unmarshal(data, &c.values)
+		case ".json":
+			err = json.Unmarshal(data, &c.values)
+		case ".toml":
+			err = toml.Unmarshal(data, &c.values)
+		default:
+			err = fmt.Errorf("%w: %q", ErrUnsupportedConfigFormat, ext)
+		}
+
+		if err != nil {
+			c.err = fmt.Errorf("failed to parse config file %s: %w", c.path, err)
+		}
+	})
+
+	return c.err
+}
+
+// check returns the load error or the errors of values that could not be
+// converted to flag values.
+func (c *configFile) check() error {
+	if err := c.load(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return errors.Join(c.lookupErrs...)
+}
+
+func (c *configFile) addLookupErr(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.lookupErrs = append(c.lookupErrs, err)
+}
+
+// lookup returns the value of the first key found in the config file. Keys are
+// matched as a whole first and then as dot-separated path into nested tables.
+func (c *configFile) lookup(keys ...string) (any, bool) {
+	if c.load() != nil {
+		return nil, false
+	}
+
+	for _, key := range keys {
+		if value, ok := c.values[key]; ok {
+			return value, true
+		}
+
+		if value, ok := lookupPath(c.values, strings.Split(key, ".")); ok {
+			return value, true
+		}
+	}
+
+	return nil, false
+}
+
+func lookupPath(values map[string]any, path []string) (any, bool) {
+	value, ok := values[path[0]]
+	if !ok {
+		return nil, false
+	}
+
+	if len(path) == 1 {
+		return value, true
+	}
+
+	nested, ok := value.(map[string]any)
+	if !ok {
+		return nil, false
+	}
+
+	return lookupPath(nested, path[1:])
+}
+
+// Lookup implements the cli.ValueSource interface. Scalars are formatted as
+// plain strings, lists of scalars are joined by comma and maps and lists of
+// maps or lists are encoded as JSON to be parsed by the map flag types. Lists
+// with items containing a comma are rejected as they would be split into
+// several items.
+func (s *configFileSource) Lookup() (string, bool) {
+	value, ok := s.file.lookup(s.keys...)
+	if !ok {
+		return "", false
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return "", true
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case []any:
+		list, err := formatConfigList(v)
+		if err != nil {
+			s.file.addLookupErr(fmt.Errorf("%s: %w", s, err))
+
+			return "", false
+		}
+
+		return list, true
+	case map[string]any, []map[string]any:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", false
+		}
+
+		return string(data), true
+	}
+
+	return fmt.Sprint(value), true
+}
+
+func formatConfigList(list []any) (string, error) {
+	nested := slices.ContainsFunc(list, func(item any) bool {
+		switch item.(type) {
+		case map[string]any, []any:
+			return true
+		}
+
+		return false
+	})
+
+	if nested {
+		data, err := json.Marshal(list)
+		if err != nil {
+			return "", fmt.Errorf("%w: %w", ErrInvalidConfigValue, err)
+		}
+
+		return string(data), nil
+	}
+
+	items := make([]string, 0, len(list))
+
+	for _, item := range list {
+		var value string
+
+		switch v := item.(type) {
+		case nil:
+		case float64:
+			value = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			value = fmt.Sprint(v)
+		}
+
+		if strings.Contains(value, ",") {
+			return "", fmt.Errorf("%w: list item %q contains a comma", ErrInvalidConfigValue, value)
+		}
+
+		items = append(items, value)
+	}
+
+	return strings.Join(items, ","), nil
+}
+
+func (s *configFileSource) String() string {
+	return fmt.Sprintf("config file key %q", strings.Join(s.keys, ", "))
+}
+
+func (s *configFileSource) GoString() string {
+	return fmt.Sprintf("&configFileSource{keys:%q}", s.keys)
+}
+
+// configKeys returns the config file keys of a flag. Those are the flag name
+// and the plugin setting names derived from the `PLUGIN_*` environment
+// variables of the flag.
+func configKeys(name string, envs []string) []string {
+	keys := []string{name}
+
+	for _, env := range envs {
+		if setting, ok := strings.CutPrefix(env, "PLUGIN_"); ok {
+			keys = append(keys, strings.ToLower(setting))
+		}
+	}
+
+	return keys
+}
+
+// addConfigSource appends the config file as lowest priority value source to
+// all given flags. Flags without a `Sources` value source chain are ignored.
+func addConfigSource(file *configFile, flags []cli.Flag) {
+	for _, flag := range flags {
+		value := reflect.ValueOf(flag)
+		if value.Kind() != reflect.Pointer || value.Elem().Kind() != reflect.Struct {
+			continue
+		}
+
+		field := value.Elem().FieldByName("Sources")
+		if !field.IsValid() || !field.CanAddr() {
+			continue
+		}
+
+		sources, ok := field.Addr().Interface().(*cli.ValueSourceChain)
+		if !ok {
+			continue
+		}
+
+		sources.Chain = append(sources.Chain, &configFileSource{
+			file: file,
+			keys: configKeys(flag.Names()[0], sources.EnvKeys()),
+		})
+	}
+}
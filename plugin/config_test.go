@@ -0,0 +1,261 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	plugin_cli "github.com/thegeeklab/wp-plugin-go/v6/cli"
+	"github.com/urfave/cli/v3"
+)
+
+const (
+	testConfigYAML = `
+string_flag: from-file
+int-flag: 42
+bool_flag: true
+slice_flag:
+  - a
+  - b
+map_flag:
+  key: value
+  num: 1
+deep_map_flag:
+  group:
+    key: value
+nested:
+  flag: nested-value
+`
+	testConfigJSON = `{
+  "string_flag": "from-file",
+  "int-flag": 42,
+  "bool_flag": true,
+  "slice_flag": ["a", "b"],
+  "map_flag": {"key": "value", "num": 1},
+  "deep_map_flag": {"group": {"key": "value"}},
+  "nested": {"flag": "nested-value"}
+}`
+	testConfigTOML = `
+string_flag = "from-file"
+int-flag = 42
+bool_flag = true
+slice_flag = ["a", "b"]
+
+[map_flag]
+key = "value"
+num = 1
+
+[deep_map_flag.group]
+key = "value"
+
+[nested]
+flag = "nested-value"
+`
+)
+
+func testConfigFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "string-flag",
+			Value:   "default",
+			Sources: cli.EnvVars("PLUGIN_STRING_FLAG"),
+		},
+		&cli.IntFlag{
+			Name:    "int-flag",
+			Sources: cli.EnvVars("PLUGIN_INT_FLAG"),
+		},
+		&cli.BoolFlag{
+			Name:    "bool-flag",
+			Sources: cli.EnvVars("PLUGIN_BOOL_FLAG"),
+		},
+		&plugin_cli.StringSliceFlag{
+			Name:    "slice-flag",
+			Sources: cli.EnvVars("PLUGIN_SLICE_FLAG"),
+			Config: plugin_cli.StringSliceConfig{
+				Delimiter:    ",",
+				EscapeString: "\\",
+			},
+		},
+		&plugin_cli.StringMapFlag{
+			Name:    "map-flag",
+			Sources: cli.EnvVars("PLUGIN_MAP_FLAG"),
+		},
+		&plugin_cli.DeepStringMapFlag{
+			Name:    "deep-map-flag",
+			Sources: cli.EnvVars("PLUGIN_DEEP_MAP_FLAG"),
+		},
+		&cli.StringFlag{
+			Name: "nested.flag",
+		},
+		&cli.StringFlag{
+			Name:    "unset-flag",
+			Value:   "default",
+			Sources: cli.EnvVars("PLUGIN_UNSET_FLAG"),
+		},
+	}
+}
+
+func TestConfigFileSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{name: "yaml", file: "config.yaml", content: testConfigYAML},
+		{name: "json", file: "config.json", content: testConfigJSON},
+		{name: "toml", file: "config.toml", content: testConfigTOML},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			assert.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+
+			cmd := &cli.Command{
+				Name:  "test",
+				Flags: testConfigFlags(),
+				Action: func(_ context.Context, _ *cli.Command) error {
+					return nil
+				},
+			}
+
+			config := newConfigFile(path)
+			addConfigSource(config, cmd.Flags)
+
+			assert.NoError(t, cmd.Run(t.Context(), []string{"test"}))
+			assert.NoError(t, config.load())
+
+			assert.Equal(t, "from-file", cmd.String("string-flag"))
+			assert.Equal(t, 42, cmd.Int("int-flag"))
+			assert.True(t, cmd.Bool("bool-flag"))
+			assert.Equal(t, []string{"a", "b"}, cmd.Value("slice-flag"))
+			assert.Equal(t, map[string]string{"key": "value", "num": "1"}, cmd.Value("map-flag"))
+			assert.Equal(t, map[string]map[string]string{"group": {"key": "value"}}, cmd.Value("deep-map-flag"))
+			assert.Equal(t, "nested-value", cmd.String("nested.flag"))
+			assert.Equal(t, "default", cmd.String("unset-flag"))
+		})
+	}
+}
+
+func TestConfigFilePrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(testConfigYAML), 0o600))
+
+	t.Setenv("PLUGIN_STRING_FLAG", "from-env")
+	t.Setenv("PLUGIN_INT_FLAG", "7")
+
+	cmd := &cli.Command{
+		Name:  "test",
+		Flags: testConfigFlags(),
+		Action: func(_ context.Context, _ *cli.Command) error {
+			return nil
+		},
+	}
+
+	addConfigSource(newConfigFile(path), cmd.Flags)
+
+	assert.NoError(t, cmd.Run(t.Context(), []string{"test", "--int-flag", "1"}))
+
+	assert.Equal(t, 1, cmd.Int("int-flag"))
+	assert.Equal(t, "from-env", cmd.String("string-flag"))
+	assert.True(t, cmd.Bool("bool-flag"))
+}
+
+func TestConfigFileLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		wantErr bool
+	}{
+		{name: "valid yaml", file: "config.yml", content: "key: value"},
+		{name: "invalid json", file: "config.json", content: "{", wantErr: true},
+		{name: "unsupported format", file: "config.ini", content: "key=value", wantErr: true},
+		{name: "missing file", file: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "missing.yaml")
+
+			if tt.file != "" {
+				path = filepath.Join(t.TempDir(), tt.file)
+				assert.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+			}
+
+			err := newConfigFile(path).load()
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestConfigFileLists(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "scalars",
+			file:    "config.yaml",
+			content: "list: [a, 1, 1.5, true]",
+			want:    "a,1,1.5,true",
+		},
+		{
+			name:    "yaml maps",
+			file:    "config.yaml",
+			content: "list: [{name: a, tags: [x, y]}, b]",
+			want:    `[{"name":"a","tags":["x","y"]},"b"]`,
+		},
+		{
+			name:    "toml tables",
+			file:    "config.toml",
+			content: "[[list]]\nname = \"a,b\"",
+			want:    `[{"name":"a,b"}]`,
+		},
+		{
+			name:    "item with comma",
+			file:    "config.json",
+			content: `{"list": ["a,b", "c"]}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			assert.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+
+			cmd := &cli.Command{
+				Name:   "test",
+				Flags:  []cli.Flag{&cli.StringFlag{Name: "list", Sources: cli.EnvVars("PLUGIN_LIST")}},
+				Action: func(_ context.Context, _ *cli.Command) error { return nil },
+			}
+
+			config := newConfigFile(path)
+			addConfigSource(config, cmd.Flags)
+
+			assert.NoError(t, cmd.Run(t.Context(), []string{"test"}))
+
+			if tt.wantErr {
+				assert.ErrorIs(t, config.check(), ErrInvalidConfigValue)
+				assert.Empty(t, cmd.String("list"))
+
+				return
+			}
+
+			assert.NoError(t, config.check())
+			assert.Equal(t, tt.want, cmd.String("list"))
+		})
+	}
+}
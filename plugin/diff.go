@@ -0,0 +1,63 @@
+package plugin
+
+import (
+	"strings"
+)
+
+// CommitDiff defines what changed between the previous and the current commit.
+type CommitDiff struct {
+	// Whether the branch differs from the branch of the previous commit.
+	BranchChanged bool   `json:"branch_changed"`
+	PrevBranch    string `json:"prev_branch"`
+	Branch        string `json:"branch"`
+	// Tag of the current commit if it differs from the tag of the previous
+	// commit.
+	NewTag string `json:"new_tag"`
+	// Whether the author differs from the author of the previous commit.
+	AuthorChanged bool   `json:"author_changed"`
+	PrevAuthor    Author `json:"prev_author"`
+	Author        Author `json:"author"`
+}
+
+// Diff returns the changes between the previous and the current commit. Values
+// that are unknown for one of the commits are not reported as changed.
+func (m Metadata) Diff() CommitDiff {
+	prev, curr := m.Prev, m.Curr
+
+	diff := CommitDiff{
+		PrevBranch: prev.Branch,
+		Branch:     curr.Branch,
+		PrevAuthor: prev.Author,
+		Author:     curr.Author,
+	}
+
+	diff.BranchChanged = prev.Branch != "" && curr.Branch != "" && prev.Branch != curr.Branch
+
+	if curr.Tag != "" && curr.Tag != prev.Tag {
+		diff.NewTag = curr.Tag
+	}
+
+	diff.AuthorChanged = authorChanged(prev.Author, curr.Author)
+
+	return diff
+}
+
+// Changed reports whether anything changed between the previous and the
+// current commit.
+func (d CommitDiff) Changed() bool {
+	return d.BranchChanged || d.NewTag != "" || d.AuthorChanged
+}
+
+// authorChanged compares authors by email and falls back to the name if the
+// email of one of the authors is unknown.
+func authorChanged(prev, curr Author) bool {
+	if prev.Email != "" && curr.Email != "" {
+		return !strings.EqualFold(prev.Email, curr.Email)
+	}
+
+	if prev.Name != "" && curr.Name != "" {
+		return prev.Name != curr.Name
+	}
+
+	return false
+}
@@ -0,0 +1,68 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetadataDiff(t *testing.T) {
+	octocat := Author{Name: "Octo Cat", Email: "octocat@example.com"}
+
+	tests := []struct {
+		name string
+		prev Commit
+		curr Commit
+		want CommitDiff
+	}{
+		{
+			name: "unchanged",
+			prev: Commit{Branch: "main", Author: octocat},
+			curr: Commit{Branch: "main", Author: Author{Name: "octocat", Email: "OctoCat@example.com"}},
+			want: CommitDiff{
+				PrevBranch: "main",
+				Branch:     "main",
+				PrevAuthor: octocat,
+				Author:     Author{Name: "octocat", Email: "OctoCat@example.com"},
+			},
+		},
+		{
+			name: "branch switch, new tag and author change",
+			prev: Commit{Branch: "main", Tag: "v1.0.0", Author: octocat},
+			curr: Commit{Branch: "release", Tag: "v1.1.0", Author: Author{Name: "Hubot", Email: "hubot@example.com"}},
+			want: CommitDiff{
+				BranchChanged: true,
+				PrevBranch:    "main",
+				Branch:        "release",
+				NewTag:        "v1.1.0",
+				AuthorChanged: true,
+				PrevAuthor:    octocat,
+				Author:        Author{Name: "Hubot", Email: "hubot@example.com"},
+			},
+		},
+		{
+			name: "author compared by name without email",
+			prev: Commit{Author: Author{Name: "octocat"}},
+			curr: Commit{Author: Author{Name: "hubot", Email: "hubot@example.com"}},
+			want: CommitDiff{
+				AuthorChanged: true,
+				PrevAuthor:    Author{Name: "octocat"},
+				Author:        Author{Name: "hubot", Email: "hubot@example.com"},
+			},
+		},
+		{
+			name: "unknown previous commit",
+			curr: Commit{Branch: "main", Tag: "v1.0.0", Author: octocat},
+			want: CommitDiff{Branch: "main", NewTag: "v1.0.0", Author: octocat},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Metadata{Prev: tt.prev, Curr: tt.curr}.Diff()
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.want.BranchChanged || tt.want.NewTag != "" || tt.want.AuthorChanged, got.Changed())
+		})
+	}
+}
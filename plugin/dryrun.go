@@ -0,0 +1,16 @@
+package plugin
+
+import (
+	"github.com/urfave/cli/v3"
+)
+
+func dryRunFlags(category string) []cli.Flag {
+	return []cli.Flag{
+		&cli.BoolFlag{
+			Name:     "dry-run",
+			Usage:    "trace commands and file changes instead of executing them",
+			Sources:  cli.EnvVars("PLUGIN_DRY_RUN"),
+			Category: category,
+		},
+	}
+}
@@ -0,0 +1,137 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Category defines the category of a plugin error.
+type Category string
+
+const (
+	CategoryUsage           Category = "usage"
+	CategoryConfig          Category = "config"
+	CategoryNetwork         Category = "network"
+	CategoryExternalCommand Category = "external-command"
+	CategoryInternal        Category = "internal"
+)
+
+// Exit codes of the plugin process. Apart from the generic failure code they
+// follow the BSD sysexits convention.
+const (
+	ExitCodeSuccess         = 0
+	ExitCodeInternal        = 1
+	ExitCodeUsage           = 64
+	ExitCodeNetwork         = 69
+	ExitCodeConfig          = 78
+	ExitCodeExternalCommand = 127
+)
+
+// Error is a plugin error with a category, a process exit code and an optional
+// hint for the user on how to resolve the error.
+type Error struct {
+	Err      error
+	Category Category
+	Code     int
+	Hint     string
+}
+
+// exitCoder is implemented by errors that carry a process exit status, e.g.
+// *exec.ExitError.
+type exitCoder interface {
+	ExitCode() int
+}
+
+// NewError wraps the given error into an Error of the given category. The exit
+// code defaults to the exit code of the category.
+func NewError(category Category, err error) *Error {
+	return &Error{
+		Err:      err,
+		Category: category,
+		Code:     category.ExitCode(),
+	}
+}
+
+// NewCommandError wraps the error of an external command into an Error of the
+// external-command category. If the error carries the exit status of the command,
+// it is used as exit code.
+func NewCommandError(err error) *Error {
+	perr := NewError(CategoryExternalCommand, err)
+
+	var exitErr exitCoder
+	if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+		perr.Code = exitErr.ExitCode()
+	}
+
+	return perr
+}
+
+// AsError converts any error into an Error. Errors that already are or wrap an
+// Error are returned as-is, errors carrying an exit status are treated as
+// external command errors and all other errors are considered internal.
+func AsError(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	var perr *Error
+	if errors.As(err, &perr) {
+		return perr
+	}
+
+	var exitErr exitCoder
+	if errors.As(err, &exitErr) {
+		return NewCommandError(err)
+	}
+
+	return NewError(CategoryInternal, err)
+}
+
+// WithHint sets the hint of the error.
+func (e *Error) WithHint(format string, args ...any) *Error {
+	e.Hint = fmt.Sprintf(format, args...)
+
+	return e
+}
+
+// WithCode overrides the exit code of the error.
+func (e *Error) WithCode(code int) *Error {
+	e.Code = code
+
+	return e
+}
+
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return string(e.Category) + " error"
+	}
+
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the process exit code of the error.
+func (e *Error) ExitCode() int {
+	return e.Code
+}
+
+// ExitCode returns the default process exit code of the category.
+func (c Category) ExitCode() int {
+	switch c {
+	case CategoryUsage:
+		return ExitCodeUsage
+	case CategoryConfig:
+		return ExitCodeConfig
+	case CategoryNetwork:
+		return ExitCodeNetwork
+	case CategoryExternalCommand:
+		return ExitCodeExternalCommand
+	case CategoryInternal:
+		return ExitCodeInternal
+	}
+
+	return ExitCodeInternal
+}
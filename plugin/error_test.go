@@ -0,0 +1,72 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errDummy = errors.New("dummy error")
+
+func TestAsError(t *testing.T) {
+	exitErr := exec.CommandContext(t.Context(), "sh", "-c", "exit 3").Run()
+
+	tests := []struct {
+		name         string
+		err          error
+		wantCategory Category
+		wantCode     int
+		wantHint     string
+	}{
+		{
+			name:         "plain error",
+			err:          errDummy,
+			wantCategory: CategoryInternal,
+			wantCode:     ExitCodeInternal,
+		},
+		{
+			name:         "config error",
+			err:          NewError(CategoryConfig, errDummy).WithHint("check %s", "settings"),
+			wantCategory: CategoryConfig,
+			wantCode:     ExitCodeConfig,
+			wantHint:     "check settings",
+		},
+		{
+			name:         "wrapped network error",
+			err:          fmt.Errorf("wrapped: %w", NewError(CategoryNetwork, errDummy)),
+			wantCategory: CategoryNetwork,
+			wantCode:     ExitCodeNetwork,
+		},
+		{
+			name:         "custom exit code",
+			err:          NewError(CategoryUsage, errDummy).WithCode(2),
+			wantCategory: CategoryUsage,
+			wantCode:     2,
+		},
+		{
+			name:         "command exit status",
+			err:          fmt.Errorf("git failed: %w", exitErr),
+			wantCategory: CategoryExternalCommand,
+			wantCode:     3,
+		},
+		{
+			name:         "command start error",
+			err:          NewCommandError(errDummy),
+			wantCategory: CategoryExternalCommand,
+			wantCode:     ExitCodeExternalCommand,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := AsError(tt.err)
+
+			assert.Equal(t, tt.wantCategory, got.Category)
+			assert.Equal(t, tt.wantCode, got.ExitCode())
+			assert.Equal(t, tt.wantHint, got.Hint)
+		})
+	}
+}
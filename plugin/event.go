@@ -0,0 +1,100 @@
+package plugin
+
+import "slices"
+
+// Event defines the event that triggered a pipeline.
+type Event string
+
+const (
+	EventPush                Event = "push"
+	EventPullRequest         Event = "pull_request"
+	EventPullRequestClosed   Event = "pull_request_closed"
+	EventPullRequestMetadata Event = "pull_request_metadata"
+	EventTag                 Event = "tag"
+	EventRelease             Event = "release"
+	EventDeployment          Event = "deployment"
+	EventCron                Event = "cron"
+	EventManual              Event = "manual"
+)
+
+// Events returns all known pipeline events.
+func Events() []Event {
+	return []Event{
+		EventPush,
+		EventPullRequest,
+		EventPullRequestClosed,
+		EventPullRequestMetadata,
+		EventTag,
+		EventRelease,
+		EventDeployment,
+		EventCron,
+		EventManual,
+	}
+}
+
+// Valid reports whether the event is a known pipeline event.
+func (e Event) Valid() bool {
+	return slices.Contains(Events(), e)
+}
+
+func (e Event) String() string {
+	return string(e)
+}
+
+// IsPush reports whether the pipeline was triggered by a push.
+func (m Metadata) IsPush() bool {
+	return m.Pipeline.Event == EventPush
+}
+
+// IsPullRequest reports whether the pipeline was triggered by a pull request,
+// including closed pull requests and pull request metadata changes.
+func (m Metadata) IsPullRequest() bool {
+	switch m.Pipeline.Event {
+	case EventPullRequest, EventPullRequestClosed, EventPullRequestMetadata:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsTag reports whether the pipeline was triggered by a tag.
+func (m Metadata) IsTag() bool {
+	return m.Pipeline.Event == EventTag
+}
+
+// IsRelease reports whether the pipeline was triggered by a release.
+func (m Metadata) IsRelease() bool {
+	return m.Pipeline.Event == EventRelease
+}
+
+// IsDeployment reports whether the pipeline was triggered by a deployment.
+func (m Metadata) IsDeployment() bool {
+	return m.Pipeline.Event == EventDeployment
+}
+
+// IsCron reports whether the pipeline was triggered by a cron job.
+func (m Metadata) IsCron() bool {
+	return m.Pipeline.Event == EventCron
+}
+
+// IsManual reports whether the pipeline was triggered manually.
+func (m Metadata) IsManual() bool {
+	return m.Pipeline.Event == EventManual
+}
+
+// IsDefaultBranch reports whether the pipeline runs on the default branch of the
+// repository. Pull requests targeting the default branch are not matched.
+func (m Metadata) IsDefaultBranch() bool {
+	if m.IsPullRequest() || m.Curr.Branch == "" {
+		return false
+	}
+
+	return m.Curr.Branch == m.Repository.Branch
+}
+
+// IsFork reports whether the pipeline was triggered by a pull request from a
+// forked repository. It is false if the forge does not report the origin of
+// the pull request.
+func (m Metadata) IsFork() bool {
+	return m.IsPullRequest() && m.Curr.PullRequestFromFork
+}
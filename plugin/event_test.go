@@ -0,0 +1,160 @@
+package plugin
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventValid(t *testing.T) {
+	for _, event := range Events() {
+		assert.True(t, event.Valid(), event)
+	}
+
+	assert.False(t, Event("").Valid())
+	assert.False(t, Event("merge_request").Valid())
+}
+
+func TestMetadataEventPredicates(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata Metadata
+		want     []string
+	}{
+		{
+			name: "push to default branch",
+			metadata: Metadata{
+				Repository: Repository{Branch: "main"},
+				Pipeline:   Pipeline{Event: EventPush},
+				Curr:       Commit{Branch: "main"},
+			},
+			want: []string{"push", "default-branch"},
+		},
+		{
+			name: "push to feature branch",
+			metadata: Metadata{
+				Repository: Repository{Branch: "main"},
+				Pipeline:   Pipeline{Event: EventPush},
+				Curr:       Commit{Branch: "feature"},
+			},
+			want: []string{"push"},
+		},
+		{
+			name: "pull request to default branch",
+			metadata: Metadata{
+				Repository: Repository{Branch: "main"},
+				Pipeline:   Pipeline{Event: EventPullRequest},
+				Curr:       Commit{Branch: "main"},
+			},
+			want: []string{"pull-request"},
+		},
+		{
+			name: "closed pull request from fork",
+			metadata: Metadata{
+				Pipeline: Pipeline{Event: EventPullRequestClosed},
+				Curr:     Commit{PullRequestFromFork: true},
+			},
+			want: []string{"pull-request", "fork"},
+		},
+		{
+			name: "fork flag without pull request",
+			metadata: Metadata{
+				Pipeline: Pipeline{Event: EventPush},
+				Curr:     Commit{PullRequestFromFork: true},
+			},
+			want: []string{"push"},
+		},
+		{
+			name:     "tag",
+			metadata: Metadata{Pipeline: Pipeline{Event: EventTag}},
+			want:     []string{"tag"},
+		},
+		{
+			name:     "release",
+			metadata: Metadata{Pipeline: Pipeline{Event: EventRelease}},
+			want:     []string{"release"},
+		},
+		{
+			name:     "deployment",
+			metadata: Metadata{Pipeline: Pipeline{Event: EventDeployment}},
+			want:     []string{"deployment"},
+		},
+		{
+			name: "cron on default branch",
+			metadata: Metadata{
+				Repository: Repository{Branch: "main"},
+				Pipeline:   Pipeline{Event: EventCron},
+				Curr:       Commit{Branch: "main"},
+			},
+			want: []string{"cron", "default-branch"},
+		},
+		{
+			name:     "manual",
+			metadata: Metadata{Pipeline: Pipeline{Event: EventManual}},
+			want:     []string{"manual"},
+		},
+		{
+			name:     "no branch",
+			metadata: Metadata{Pipeline: Pipeline{Event: EventPush}},
+			want:     []string{"push"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			predicates := map[string]bool{
+				"push":           tt.metadata.IsPush(),
+				"pull-request":   tt.metadata.IsPullRequest(),
+				"tag":            tt.metadata.IsTag(),
+				"release":        tt.metadata.IsRelease(),
+				"deployment":     tt.metadata.IsDeployment(),
+				"cron":           tt.metadata.IsCron(),
+				"manual":         tt.metadata.IsManual(),
+				"default-branch": tt.metadata.IsDefaultBranch(),
+				"fork":           tt.metadata.IsFork(),
+			}
+
+			for name, got := range predicates {
+				assert.Equal(t, slices.Contains(tt.want, name), got, name)
+			}
+		})
+	}
+}
+
+func TestPipelineEvent(t *testing.T) {
+	assert.Equal(t, EventTag, pipelineEvent("tag"))
+	assert.Equal(t, Event(""), pipelineEvent("custom"))
+	assert.Equal(t, Event(""), pipelineEvent(""))
+}
+
+func TestPluginUnknownEvent(t *testing.T) {
+	t.Setenv("CI_PIPELINE_EVENT", "custom")
+
+	var p *Plugin
+
+	executed := false
+
+	p = New(Options{
+		Name: "dummy",
+		Execute: func(_ context.Context) error {
+			executed = true
+
+			return nil
+		},
+	})
+	result := p.RunContext(t.Context(), []string{"dummy"})
+
+	assert.Equal(t, StatusSuccess, result.Status)
+	assert.True(t, executed)
+	assert.Equal(t, Event(""), p.Metadata.Pipeline.Event)
+}
+
+func TestUnknownPrevEvent(t *testing.T) {
+	got, err := fromEnv(t, map[string]string{"CI_PREV_PIPELINE_EVENT": "custom"}, metadataFromContext)
+	require.NoError(t, err)
+
+	assert.Equal(t, Event(""), got.PrevPipeline.Event)
+}
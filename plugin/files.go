@@ -0,0 +1,66 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/thegeeklab/wp-plugin-go/v6/file"
+	"github.com/thegeeklab/wp-plugin-go/v6/git"
+)
+
+var ErrChangedFilesUnknown = errors.New("changed files unknown")
+
+// ChangedFiles returns the files changed by the pipeline. The files of the
+// pipeline metadata are used if set, otherwise the files are read from the git
+// repository of the workspace as diff between the previous and the current
+// commit.
+func (m Metadata) ChangedFiles() ([]string, error) {
+	if len(m.Pipeline.Files) > 0 {
+		return slices.Clone(m.Pipeline.Files), nil
+	}
+
+	if m.Prev.SHA == "" || m.Curr.SHA == "" {
+		return nil, fmt.Errorf("%w: previous or current commit not set", ErrChangedFilesUnknown)
+	}
+
+	repo, err := git.Open(m.workspaceDir())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrChangedFilesUnknown, err)
+	}
+	defer repo.Close()
+
+	files, err := repo.ChangedFiles(m.Prev.SHA, m.Curr.SHA)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrChangedFilesUnknown, err)
+	}
+
+	return files, nil
+}
+
+// MatchChangedFiles returns the changed files that match any of the include
+// and none of the exclude glob patterns, and whether any file matched. Patterns
+// support `**` to match any number of directories, see file.Match. If no
+// include patterns are given, all changed files are included.
+func (m Metadata) MatchChangedFiles(include, exclude []string) ([]string, bool, error) {
+	files, err := m.ChangedFiles()
+	if err != nil {
+		return nil, false, err
+	}
+
+	matched, err := file.MatchPaths(files, include, exclude)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return matched, len(matched) > 0, nil
+}
+
+// workspaceDir returns the workspace path, defaults to the working directory.
+func (m Metadata) workspaceDir() string {
+	if m.Workspace.Path == "" {
+		return "."
+	}
+
+	return m.Workspace.Path
+}
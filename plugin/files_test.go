@@ -0,0 +1,99 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thegeeklab/wp-plugin-go/v6/internal/gittest"
+)
+
+func TestMetadataChangedFiles(t *testing.T) {
+	dir, prev := testGitRepo(t)
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "docs", "guide"), 0o700))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "docs", "guide", "setup.md"), []byte("setup\n"), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o600))
+	gittest.Run(t, dir, "add", "--all")
+	gittest.Run(t, dir, "commit", "--quiet", "--message", "docs: add guide")
+
+	curr := gittest.Run(t, dir, "rev-parse", "HEAD")
+
+	tests := []struct {
+		name     string
+		metadata Metadata
+		want     []string
+		wantErr  error
+	}{
+		{
+			name: "pipeline files",
+			metadata: Metadata{
+				Pipeline: Pipeline{Files: []string{"README.md", "docs/index.md"}},
+			},
+			want: []string{"README.md", "docs/index.md"},
+		},
+		{
+			name: "git diff",
+			metadata: Metadata{
+				Workspace: Workspace{Path: dir},
+				Prev:      Commit{SHA: prev},
+				Curr:      Commit{SHA: curr},
+			},
+			want: []string{"docs/guide/setup.md", "main.go"},
+		},
+		{
+			name: "missing previous commit",
+			metadata: Metadata{
+				Workspace: Workspace{Path: dir},
+				Curr:      Commit{SHA: curr},
+			},
+			wantErr: ErrChangedFilesUnknown,
+		},
+		{
+			name: "unknown commit",
+			metadata: Metadata{
+				Workspace: Workspace{Path: dir},
+				Prev:      Commit{SHA: "0000000000000000000000000000000000000000"},
+				Curr:      Commit{SHA: curr},
+			},
+			wantErr: ErrChangedFilesUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.metadata.ChangedFiles()
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMetadataMatchChangedFiles(t *testing.T) {
+	m := Metadata{
+		Pipeline: Pipeline{Files: []string{"README.md", "docs/index.md", "docs/guide/setup.md", "main.go"}},
+	}
+
+	got, ok, err := m.MatchChangedFiles([]string{"docs/**/*.md"}, []string{"docs/guide/**"})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"docs/index.md"}, got)
+
+	got, ok, err = m.MatchChangedFiles([]string{"charts/**"}, nil)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Empty(t, got)
+
+	_, _, err = m.MatchChangedFiles([]string{"["}, nil)
+	assert.Error(t, err)
+
+	_, _, err = Metadata{}.MatchChangedFiles(nil, nil)
+	assert.ErrorIs(t, err, ErrChangedFilesUnknown)
+}
@@ -21,28 +21,47 @@ import (
 const (
 	FlagsRepositoryCategory = "Woodpecker Repository Flags"
 	FlagsPipelineCategory   = "Woodpecker Pipeline Flags"
+	FlagsWorkflowCategory   = "Woodpecker Workflow Flags"
 	FlagsCommitCategory     = "Woodpecker Commit Flags"
 	FlagsStepCategory       = "Woodpecker Step Flags"
 	FlagsSystemCategory     = "Woodpecker System Flags"
+	FlagsForgeCategory      = "Woodpecker Forge Flags"
 	FlagsPluginCategory     = "Plugin Flags"
 )
 
 // Flags has the cli.Flags for the Woodpecker plugin.
 func Flags() []cli.Flag {
+	flags := metadataFlags()
+
+	// Plugin flags
+	flags = append(flags, loggingFlags(FlagsPluginCategory)...)
+	flags = append(flags, dryRunFlags(FlagsPluginCategory)...)
+	flags = append(flags, whenFlags(FlagsPluginCategory)...)
+	flags = append(flags, metadataProviderFlags(FlagsPluginCategory)...)
+	flags = append(flags, gitFlags(FlagsPluginCategory)...)
+	flags = append(flags, networkFlags(FlagsPluginCategory)...)
+	flags = append(flags, environmentFlags(FlagsPluginCategory)...)
+	flags = append(flags, resultFlags(FlagsPluginCategory)...)
+	flags = append(flags, outputFlags(FlagsPluginCategory)...)
+	flags = append(flags, reportFlags(FlagsPluginCategory)...)
+
+	return flags
+}
+
+// metadataFlags returns the flags read by MetadataFromContext.
+func metadataFlags() []cli.Flag {
 	flags := make([]cli.Flag, 0)
 
 	// Pipeline flags
 	flags = append(flags, repositoryFlags(FlagsRepositoryCategory)...)
 	flags = append(flags, pipelineFlags(FlagsPipelineCategory)...)
+	flags = append(flags, prevPipelineFlags(FlagsPipelineCategory)...)
+	flags = append(flags, workflowFlags(FlagsWorkflowCategory)...)
 	flags = append(flags, currFlags(FlagsCommitCategory)...)
 	flags = append(flags, prevFlags(FlagsCommitCategory)...)
 	flags = append(flags, stepFlags(FlagsStepCategory)...)
 	flags = append(flags, systemFlags(FlagsSystemCategory)...)
-
-	// Plugin flags
-	flags = append(flags, loggingFlags(FlagsPluginCategory)...)
-	flags = append(flags, networkFlags(FlagsPluginCategory)...)
-	flags = append(flags, environmentFlags(FlagsPluginCategory)...)
+	flags = append(flags, forgeFlags(FlagsForgeCategory)...)
 
 	return flags
 }
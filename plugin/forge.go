@@ -0,0 +1,35 @@
+package plugin
+
+import (
+	"github.com/urfave/cli/v3"
+)
+
+// Forge defines runtime metadata for the forge hosting the repository.
+type Forge struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+func forgeFlags(category string) []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:     "forge.type",
+			Usage:    "forge type",
+			Sources:  cli.EnvVars("CI_FORGE_TYPE"),
+			Category: category,
+		},
+		&cli.StringFlag{
+			Name:     "forge.url",
+			Usage:    "forge url",
+			Sources:  cli.EnvVars("CI_FORGE_URL"),
+			Category: category,
+		},
+	}
+}
+
+func forgeFromContext(c *cli.Command) Forge {
+	return Forge{
+		Type: c.String("forge.type"),
+		URL:  c.String("forge.url"),
+	}
+}
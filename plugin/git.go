@@ -0,0 +1,133 @@
+package plugin
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/thegeeklab/wp-plugin-go/v6/git"
+	"github.com/urfave/cli/v3"
+)
+
+func gitFlags(category string) []cli.Flag {
+	return []cli.Flag{
+		&cli.BoolFlag{
+			Name:     "git-metadata",
+			Usage:    "fill missing commit and repository metadata from the local git repository",
+			Sources:  cli.EnvVars("PLUGIN_GIT_METADATA"),
+			Category: category,
+		},
+	}
+}
+
+// fillFromGit fills empty commit and repository fields from the git repository
+// containing the given directory. Fields that are already set are kept.
+func (m *Metadata) fillFromGit(dir string) error {
+	repo, err := git.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	head, err := repo.Head()
+	if err != nil {
+		return err
+	}
+
+	if m.Curr.SHA == "" {
+		m.Curr.SHA = head.SHA
+	}
+
+	if err := m.Curr.fillFromGit(repo, head); err != nil {
+		return err
+	}
+
+	remote, err := repo.Remote()
+	if err != nil {
+		// A repository without remote is valid for local development.
+		return nil
+	}
+
+	info, err := git.ParseRemoteURL(remote.URL)
+	if err != nil {
+		return err
+	}
+
+	cloneURL, cloneSSHURL := remoteCloneURLs(remote.URL)
+
+	setDefault(&m.Repository.CloneURL, cloneURL)
+	setDefault(&m.Repository.CloneSSHURL, cloneSSHURL)
+	setDefault(&m.Repository.URL, info.WebURL())
+	setDefault(&m.Repository.Name, info.Name)
+	setDefault(&m.Repository.Owner, info.Owner)
+	setDefault(&m.Repository.Slug, info.Slug())
+
+	return nil
+}
+
+func (c *Commit) fillFromGit(repo *git.Repository, head git.Head) error {
+	commit, err := repo.Commit(c.SHA)
+	if err != nil {
+		return err
+	}
+
+	tags, err := repo.TagsAt(c.SHA)
+	if err != nil {
+		return err
+	}
+
+	if c.SHA == head.SHA {
+		setDefault(&c.Branch, head.Branch())
+	}
+
+	if len(tags) > 0 {
+		setDefault(&c.Tag, tags[0])
+	}
+
+	switch {
+	case c.Branch != "":
+		setDefault(&c.Ref, "refs/heads/"+c.Branch)
+	case c.Tag != "":
+		setDefault(&c.Ref, "refs/tags/"+c.Tag)
+	}
+
+	if c.Message == "" {
+		c.setMessage(strings.TrimRight(commit.Message, "\n"))
+	}
+
+	setDefault(&c.Author.Name, commit.Author.Name)
+	setDefault(&c.Author.Email, commit.Author.Email)
+
+	return nil
+}
+
+// remoteCloneURLs returns the remote URL either as clone URL or, for SSH and
+// scp-like remotes, as SSH clone URL. Credentials are removed from clone URLs,
+// SSH clone URLs keep the user name only.
+func remoteCloneURLs(remote string) (string, string) {
+	if !strings.Contains(remote, "://") {
+		return "", remote
+	}
+
+	u, err := url.Parse(remote)
+	if err != nil {
+		return "", ""
+	}
+
+	if u.Scheme == "ssh" || u.Scheme == "git+ssh" {
+		if u.User != nil {
+			u.User = url.User(u.User.Username())
+		}
+
+		return "", u.String()
+	}
+
+	u.User = nil
+
+	return u.String(), ""
+}
+
+func setDefault(field *string, value string) {
+	if *field == "" {
+		*field = value
+	}
+}
@@ -0,0 +1,102 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
+var ErrPanic = errors.New("panic")
+
+// ErrorFunc defines the function that is executed by the plugin if one of the
+// lifecycle stages failed. It receives the error of the failed stage.
+type ErrorFunc func(ctx context.Context, err error) error
+
+// lifecycle holds the ordered stages of a plugin run. The execute stage is set
+// per command.
+type lifecycle struct {
+	validate ExecuteFunc
+	before   ExecuteFunc
+	execute  ExecuteFunc
+	after    ExecuteFunc
+	onError  ErrorFunc
+	cleanup  ExecuteFunc
+}
+
+type stage struct {
+	name string
+	fn   ExecuteFunc
+}
+
+// run executes the validate, before, execute and after stages in order and stops
+// at the first failing stage. If a stage failed, the on-error stage is called with
+// the error. The cleanup stage always runs last. Panics in any stage are recovered
+// and returned as errors. Errors of multiple stages are joined. A skip error
+// ends the run early but does not trigger the on-error stage. It is returned
+// as-is only if the cleanup stage succeeded.
+func (l lifecycle) run(ctx context.Context) error {
+	stages := []stage{
+		{name: "validate", fn: l.validate},
+		{name: "before execute", fn: l.before},
+		{name: "execute", fn: l.execute},
+		{name: "after execute", fn: l.after},
+	}
+
+	var err error
+
+	for _, s := range stages {
+		if s.fn == nil {
+			continue
+		}
+
+		if err = s.call(ctx); err != nil {
+			break
+		}
+	}
+
+	if _, skipped := asSkip(err); err != nil && !skipped && l.onError != nil {
+		onError := stage{
+			name: "on error",
+			fn: func(ctx context.Context) error {
+				return l.onError(ctx, err)
+			},
+		}
+
+		if onErrorErr := onError.call(ctx); onErrorErr != nil {
+			err = errors.Join(err, onErrorErr)
+		}
+	}
+
+	if l.cleanup != nil {
+		cleanup := stage{name: "cleanup", fn: l.cleanup}
+
+		if cleanupErr := cleanup.call(ctx); cleanupErr != nil {
+			err = errors.Join(err, cleanupErr)
+		}
+	}
+
+	return err
+}
+
+// call runs the stage function and recovers from panics. Errors of all stages
+// except execute are prefixed with the stage name.
+func (s stage) call(ctx context.Context) error {
+	var err error
+
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("%w: %v\n%s", ErrPanic, r, debug.Stack())
+			}
+		}()
+
+		err = s.fn(ctx)
+	}()
+
+	if err != nil && s.name != "execute" {
+		return fmt.Errorf("%s: %w", s.name, err)
+	}
+
+	return err
+}
@@ -0,0 +1,158 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	errValidate = errors.New("validate error")
+	errExecute  = errors.New("execute error")
+	errCleanup  = errors.New("cleanup error")
+)
+
+func TestLifecycleRun(t *testing.T) {
+	tests := []struct {
+		name      string
+		failAt    string
+		skipAt    string
+		panicAt   string
+		wantCalls []string
+		wantErrs  []error
+		wantSkip  bool
+	}{
+		{
+			name:      "all stages succeed",
+			wantCalls: []string{"validate", "before", "execute", "after", "cleanup"},
+		},
+		{
+			name:      "validate fails",
+			failAt:    "validate",
+			wantCalls: []string{"validate", "on-error", "cleanup"},
+			wantErrs:  []error{errValidate},
+		},
+		{
+			name:      "execute fails",
+			failAt:    "execute",
+			wantCalls: []string{"validate", "before", "execute", "on-error", "cleanup"},
+			wantErrs:  []error{errExecute},
+		},
+		{
+			name:      "execute panics",
+			panicAt:   "execute",
+			wantCalls: []string{"validate", "before", "execute", "on-error", "cleanup"},
+			wantErrs:  []error{ErrPanic},
+		},
+		{
+			name:      "execute and cleanup fail",
+			failAt:    "execute",
+			panicAt:   "cleanup",
+			wantCalls: []string{"validate", "before", "execute", "on-error", "cleanup"},
+			wantErrs:  []error{errExecute, ErrPanic},
+		},
+		{
+			name:      "before skips",
+			skipAt:    "before",
+			wantCalls: []string{"validate", "before", "cleanup"},
+			wantErrs:  []error{ErrSkip},
+			wantSkip:  true,
+		},
+		{
+			name:      "execute skips, cleanup fails",
+			skipAt:    "execute",
+			failAt:    "cleanup",
+			wantCalls: []string{"validate", "before", "execute", "cleanup"},
+			wantErrs:  []error{ErrSkip, errCleanup},
+		},
+		{
+			name:      "cleanup fails",
+			failAt:    "cleanup",
+			wantCalls: []string{"validate", "before", "execute", "after", "cleanup"},
+			wantErrs:  []error{errCleanup},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := make([]string, 0)
+			errs := map[string]error{
+				"validate": errValidate,
+				"execute":  errExecute,
+				"cleanup":  errCleanup,
+			}
+
+			fn := func(name string) ExecuteFunc {
+				return func(_ context.Context) error {
+					calls = append(calls, name)
+
+					if tt.panicAt == name {
+						panic(name)
+					}
+
+					if tt.skipAt == name {
+						return Skip("nothing to do")
+					}
+
+					if tt.failAt == name {
+						return errs[name]
+					}
+
+					return nil
+				}
+			}
+
+			var onErrorErr error
+
+			l := lifecycle{
+				validate: fn("validate"),
+				before:   fn("before"),
+				execute:  fn("execute"),
+				after:    fn("after"),
+				onError: func(_ context.Context, err error) error {
+					calls = append(calls, "on-error")
+					onErrorErr = err
+
+					return nil
+				},
+				cleanup: fn("cleanup"),
+			}
+
+			err := l.run(t.Context())
+
+			assert.Equal(t, tt.wantCalls, calls)
+
+			if len(tt.wantErrs) == 0 {
+				assert.NoError(t, err)
+				assert.NoError(t, onErrorErr)
+
+				return
+			}
+
+			for _, want := range tt.wantErrs {
+				assert.ErrorIs(t, err, want)
+			}
+
+			_, skipped := asSkip(err)
+			assert.Equal(t, tt.wantSkip, skipped)
+
+			if tt.failAt != "cleanup" && tt.skipAt == "" {
+				assert.ErrorIs(t, onErrorErr, tt.wantErrs[0])
+			}
+		})
+	}
+}
+
+func TestPluginSkipCleanupFails(t *testing.T) {
+	result := New(Options{
+		Name:    "dummy",
+		Execute: func(_ context.Context) error { return Skip("nothing to do") },
+		Cleanup: func(_ context.Context) error { return errCleanup },
+	}).RunContext(t.Context(), []string{"dummy"})
+
+	assert.Equal(t, StatusFailure, result.Status)
+	assert.Equal(t, ExitCodeInternal, result.ExitCode)
+	assert.Contains(t, result.Reason, errCleanup.Error())
+}
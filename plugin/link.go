@@ -0,0 +1,265 @@
+package plugin
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Forge types as reported by Woodpecker.
+const (
+	ForgeGitea     = "gitea"
+	ForgeForgejo   = "forgejo"
+	ForgeGitHub    = "github"
+	ForgeGitLab    = "gitlab"
+	ForgeBitbucket = "bitbucket"
+)
+
+// Links builds web URLs of the forge for commits, compare views, pull requests,
+// tags, releases and files of the repository. All methods return an empty
+// string if the forge type is unknown or the URL cannot be built from the
+// metadata.
+type Links struct {
+	forge    string
+	metadata Metadata
+}
+
+// forgePaths defines the URL paths of a forge relative to the repository URL.
+type forgePaths struct {
+	commit      func(sha string) []string
+	compare     func(base, head string) string
+	pullRequest func(number string) []string
+	tag         func(name string) []string
+	release     func(name string) []string
+	file        func(ref, path string) []string
+}
+
+//nolint:gochecknoglobals
+var forges = map[string]forgePaths{
+	ForgeGitHub: {
+		commit:      func(sha string) []string { return []string{"commit", sha} },
+		compare:     func(base, head string) string { return "compare/" + base + "..." + head },
+		pullRequest: func(number string) []string { return []string{"pull", number} },
+		tag:         func(name string) []string { return []string{"tree", name} },
+		release:     func(name string) []string { return []string{"releases", "tag", name} },
+		file:        func(ref, path string) []string { return []string{"blob", ref, path} },
+	},
+	ForgeGitea: {
+		commit:      func(sha string) []string { return []string{"commit", sha} },
+		compare:     func(base, head string) string { return "compare/" + base + "..." + head },
+		pullRequest: func(number string) []string { return []string{"pulls", number} },
+		tag:         func(name string) []string { return []string{"src", "tag", name} },
+		release:     func(name string) []string { return []string{"releases", "tag", name} },
+		file:        func(ref, path string) []string { return []string{"src", ref, path} },
+	},
+	ForgeGitLab: {
+		commit:      func(sha string) []string { return []string{"-", "commit", sha} },
+		compare:     func(base, head string) string { return "-/compare/" + base + "..." + head },
+		pullRequest: func(number string) []string { return []string{"-", "merge_requests", number} },
+		tag:         func(name string) []string { return []string{"-", "tags", name} },
+		release:     func(name string) []string { return []string{"-", "releases", name} },
+		file:        func(ref, path string) []string { return []string{"-", "blob", ref, path} },
+	},
+	ForgeBitbucket: {
+		commit: func(sha string) []string { return []string{"commits", sha} },
+		compare: func(base, head string) string {
+			return "branches/compare/" + url.PathEscape(head) + "%0D" + url.PathEscape(base)
+		},
+		pullRequest: func(number string) []string { return []string{"pull-requests", number} },
+		tag:         func(name string) []string { return []string{"src", name} },
+		// Bitbucket has no releases, the tag is used instead.
+		release: func(name string) []string { return []string{"src", name} },
+		file:    func(ref, path string) []string { return []string{"src", ref, path} },
+	},
+}
+
+// Links returns the link builder for the forge of the repository. If the forge
+// type is not set, it is derived from well-known hosts of the repository URL.
+func (m Metadata) Links() Links {
+	forge := strings.ToLower(m.Forge.Type)
+
+	if forge == ForgeForgejo {
+		forge = ForgeGitea
+	}
+
+	if forge == "" {
+		forge = forgeFromURL(m.Repository.URL)
+	}
+
+	return Links{forge: forge, metadata: m}
+}
+
+func forgeFromURL(repoURL string) string {
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return ""
+	}
+
+	switch u.Hostname() {
+	case "github.com":
+		return ForgeGitHub
+	case "gitlab.com":
+		return ForgeGitLab
+	case "bitbucket.org":
+		return ForgeBitbucket
+	case "codeberg.org", "gitea.com":
+		return ForgeGitea
+	}
+
+	return ""
+}
+
+// Repository returns the URL of the repository.
+func (l Links) Repository() string {
+	return l.metadata.Repository.URL
+}
+
+// Commit returns the URL of the given commit.
+func (l Links) Commit(sha string) string {
+	paths, ok := l.paths(sha)
+	if !ok {
+		return ""
+	}
+
+	return l.join(paths.commit(sha)...)
+}
+
+// Compare returns the URL of the changes between the base and head commit or
+// reference.
+func (l Links) Compare(base, head string) string {
+	paths, ok := l.paths(base, head)
+	if !ok {
+		return ""
+	}
+
+	repoURL := strings.TrimSuffix(l.metadata.Repository.URL, "/")
+
+	return repoURL + "/" + paths.compare(base, head)
+}
+
+// PullRequest returns the URL of the pull request with the given number.
+func (l Links) PullRequest(number int64) string {
+	if number <= 0 {
+		return ""
+	}
+
+	paths, ok := l.paths()
+	if !ok {
+		return ""
+	}
+
+	return l.join(paths.pullRequest(strconv.FormatInt(number, 10))...)
+}
+
+// Tag returns the URL of the given tag.
+func (l Links) Tag(name string) string {
+	paths, ok := l.paths(name)
+	if !ok {
+		return ""
+	}
+
+	return l.join(paths.tag(name)...)
+}
+
+// Release returns the URL of the release of the given tag.
+func (l Links) Release(tag string) string {
+	paths, ok := l.paths(tag)
+	if !ok {
+		return ""
+	}
+
+	return l.join(paths.release(tag)...)
+}
+
+// File returns the URL of the file at the given path and commit or reference.
+func (l Links) File(ref, path string) string {
+	paths, ok := l.paths(ref, path)
+	if !ok {
+		return ""
+	}
+
+	return l.join(paths.file(ref, strings.TrimPrefix(path, "/"))...)
+}
+
+// CurrCommit returns the URL of the current commit.
+func (l Links) CurrCommit() string {
+	if l.metadata.Curr.URL != "" {
+		return l.metadata.Curr.URL
+	}
+
+	return l.Commit(l.metadata.Curr.SHA)
+}
+
+// CompareWithPrev returns the URL of the changes between the previous and the
+// current commit.
+func (l Links) CompareWithPrev() string {
+	return l.Compare(l.metadata.Prev.SHA, l.metadata.Curr.SHA)
+}
+
+// CurrPullRequest returns the URL of the current pull request.
+func (l Links) CurrPullRequest() string {
+	return l.PullRequest(l.metadata.Curr.PullRequest)
+}
+
+// CurrTag returns the URL of the current tag.
+func (l Links) CurrTag() string {
+	return l.Tag(l.metadata.Curr.Tag)
+}
+
+// CurrRelease returns the URL of the release of the current tag.
+func (l Links) CurrRelease() string {
+	return l.Release(l.metadata.Curr.Tag)
+}
+
+// Pipeline returns the URL of the pipeline. If the pipeline URL is not set, it
+// is built from the URL of the CI system.
+func (l Links) Pipeline() string {
+	m := l.metadata
+
+	if m.Pipeline.URL != "" {
+		return m.Pipeline.URL
+	}
+
+	if m.System.URL == "" || m.Repository.Slug == "" || m.Pipeline.Number <= 0 {
+		return ""
+	}
+
+	pipelineURL, err := url.JoinPath(
+		m.System.URL,
+		"repos",
+		m.Repository.Slug,
+		"pipeline",
+		strconv.FormatInt(m.Pipeline.Number, 10),
+	)
+	if err != nil {
+		return ""
+	}
+
+	return pipelineURL
+}
+
+// paths returns the URL paths of the forge if the repository URL and all
+// given values are set.
+func (l Links) paths(values ...string) (forgePaths, bool) {
+	paths, ok := forges[l.forge]
+	if !ok || l.metadata.Repository.URL == "" {
+		return forgePaths{}, false
+	}
+
+	for _, value := range values {
+		if value == "" {
+			return forgePaths{}, false
+		}
+	}
+
+	return paths, true
+}
+
+func (l Links) join(elem ...string) string {
+	link, err := url.JoinPath(l.metadata.Repository.URL, elem...)
+	if err != nil {
+		return ""
+	}
+
+	return link
+}
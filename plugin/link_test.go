@@ -0,0 +1,151 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinks(t *testing.T) {
+	type links struct {
+		commit, compare, pullRequest, tag, release, file string
+	}
+
+	tests := []struct {
+		name    string
+		forge   string
+		repoURL string
+		want    links
+	}{
+		{
+			name:    "github",
+			forge:   "github",
+			repoURL: "https://github.com/octocat/hello-world",
+			want: links{
+				commit:      "https://github.com/octocat/hello-world/commit/def",
+				compare:     "https://github.com/octocat/hello-world/compare/abc...def",
+				pullRequest: "https://github.com/octocat/hello-world/pull/7",
+				tag:         "https://github.com/octocat/hello-world/tree/v1.0.0",
+				release:     "https://github.com/octocat/hello-world/releases/tag/v1.0.0",
+				file:        "https://github.com/octocat/hello-world/blob/def/docs/index.md",
+			},
+		},
+		{
+			name:    "forgejo",
+			forge:   "forgejo",
+			repoURL: "https://example.com/octocat/hello-world",
+			want: links{
+				commit:      "https://example.com/octocat/hello-world/commit/def",
+				compare:     "https://example.com/octocat/hello-world/compare/abc...def",
+				pullRequest: "https://example.com/octocat/hello-world/pulls/7",
+				tag:         "https://example.com/octocat/hello-world/src/tag/v1.0.0",
+				release:     "https://example.com/octocat/hello-world/releases/tag/v1.0.0",
+				file:        "https://example.com/octocat/hello-world/src/def/docs/index.md",
+			},
+		},
+		{
+			name:    "gitlab detected by host",
+			repoURL: "https://gitlab.com/group/sub/hello-world",
+			want: links{
+				commit:      "https://gitlab.com/group/sub/hello-world/-/commit/def",
+				compare:     "https://gitlab.com/group/sub/hello-world/-/compare/abc...def",
+				pullRequest: "https://gitlab.com/group/sub/hello-world/-/merge_requests/7",
+				tag:         "https://gitlab.com/group/sub/hello-world/-/tags/v1.0.0",
+				release:     "https://gitlab.com/group/sub/hello-world/-/releases/v1.0.0",
+				file:        "https://gitlab.com/group/sub/hello-world/-/blob/def/docs/index.md",
+			},
+		},
+		{
+			name:    "bitbucket",
+			forge:   "bitbucket",
+			repoURL: "https://bitbucket.org/octocat/hello-world",
+			want: links{
+				commit:      "https://bitbucket.org/octocat/hello-world/commits/def",
+				compare:     "https://bitbucket.org/octocat/hello-world/branches/compare/def%0Dabc",
+				pullRequest: "https://bitbucket.org/octocat/hello-world/pull-requests/7",
+				tag:         "https://bitbucket.org/octocat/hello-world/src/v1.0.0",
+				release:     "https://bitbucket.org/octocat/hello-world/src/v1.0.0",
+				file:        "https://bitbucket.org/octocat/hello-world/src/def/docs/index.md",
+			},
+		},
+		{
+			name:    "unknown forge",
+			repoURL: "https://example.com/octocat/hello-world",
+		},
+		{
+			name:  "missing repository url",
+			forge: "github",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := Metadata{
+				Forge:      Forge{Type: tt.forge},
+				Repository: Repository{URL: tt.repoURL},
+				Curr:       Commit{SHA: "def", PullRequest: 7, Tag: "v1.0.0"},
+				Prev:       Commit{SHA: "abc"},
+			}
+			l := m.Links()
+
+			assert.Equal(t, tt.want.commit, l.CurrCommit())
+			assert.Equal(t, tt.want.compare, l.CompareWithPrev())
+			assert.Equal(t, tt.want.pullRequest, l.CurrPullRequest())
+			assert.Equal(t, tt.want.tag, l.CurrTag())
+			assert.Equal(t, tt.want.release, l.CurrRelease())
+			assert.Equal(t, tt.want.file, l.File("def", "/docs/index.md"))
+		})
+	}
+}
+
+func TestLinksMissingValues(t *testing.T) {
+	l := Metadata{
+		Forge:      Forge{Type: "github"},
+		Repository: Repository{URL: "https://github.com/octocat/hello-world"},
+	}.Links()
+
+	assert.Empty(t, l.CurrCommit())
+	assert.Empty(t, l.CompareWithPrev())
+	assert.Empty(t, l.CurrPullRequest())
+	assert.Empty(t, l.CurrTag())
+	assert.Equal(t, "https://github.com/octocat/hello-world/compare/main...feature/x", l.Compare("main", "feature/x"))
+	assert.Equal(t, "https://github.com/octocat/hello-world", l.Repository())
+}
+
+func TestLinksPipeline(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata Metadata
+		want     string
+	}{
+		{
+			name: "pipeline url set",
+			metadata: Metadata{
+				Pipeline: Pipeline{URL: "https://ci.example.com/custom", Number: 42},
+			},
+			want: "https://ci.example.com/custom",
+		},
+		{
+			name: "built from system url",
+			metadata: Metadata{
+				System:     System{URL: "https://ci.example.com"},
+				Repository: Repository{Slug: "octocat/hello-world"},
+				Pipeline:   Pipeline{Number: 42},
+			},
+			want: "https://ci.example.com/repos/octocat/hello-world/pipeline/42",
+		},
+		{
+			name: "missing system url",
+			metadata: Metadata{
+				Repository: Repository{Slug: "octocat/hello-world"},
+				Pipeline:   Pipeline{Number: 42},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.metadata.Links().Pipeline())
+		})
+	}
+}
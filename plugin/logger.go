@@ -16,6 +16,7 @@ package plugin
 
 import (
 	"context"
+	"io"
 	"os"
 
 	"github.com/rs/zerolog"
@@ -37,6 +38,11 @@ func loggingFlags(category string) []cli.Flag {
 
 // SetupConsoleLogger sets up the console logger.
 func SetupConsoleLogger(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	return setupConsoleLogger(ctx, cmd, os.Stdout)
+}
+
+// setupConsoleLogger sets up the console logger writing to the given writer.
+func setupConsoleLogger(ctx context.Context, cmd *cli.Command, out io.Writer) (context.Context, error) {
 	level := "info"
 
 	if cmd != nil {
@@ -51,7 +57,7 @@ func SetupConsoleLogger(ctx context.Context, cmd *cli.Command) (context.Context,
 	zerolog.SetGlobalLevel(lvl)
 
 	log.Logger = zerolog.New(zerolog.ConsoleWriter{
-		Out:          os.Stdout,
+		Out:          out,
 		PartsExclude: []string{zerolog.TimestampFieldName},
 	}).With().Timestamp().Logger()
 
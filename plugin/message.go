@@ -0,0 +1,180 @@
+package plugin
+
+import (
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+// Well-known commit message trailer keys.
+const (
+	TrailerSignedOffBy    = "Signed-off-by"
+	TrailerCoAuthoredBy   = "Co-authored-by"
+	TrailerRefs           = "Refs"
+	TrailerBreakingChange = "BREAKING CHANGE"
+)
+
+type (
+	// ConventionalCommit defines the header of a commit message following the
+	// Conventional Commits specification, e.g. `feat(api)!: add endpoint`.
+	ConventionalCommit struct {
+		Type     string `json:"type"`
+		Scope    string `json:"scope"`
+		Subject  string `json:"subject"`
+		Breaking bool   `json:"breaking"`
+	}
+
+	// Trailer defines a git trailer of a commit message, e.g.
+	// `Signed-off-by: Octo Cat <octocat@example.com>`.
+	Trailer struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}
+)
+
+var (
+	conventionalHeader = regexp.MustCompile(`^([a-zA-Z]+)(?:\(([^()]*)\))?(!)?: +(.+)$`)
+	trailerLine        = regexp.MustCompile(`^(BREAKING[ -]CHANGE|[a-zA-Z0-9][a-zA-Z0-9-]*)(?:: +(.*)| +(#.*))$`)
+	//nolint:gochecknoglobals
+	skipDirectives = []string{
+		"[skip ci]",
+		"[ci skip]",
+		"[no ci]",
+		"[skip woodpecker]",
+		"[woodpecker skip]",
+		"***no_ci***",
+	}
+)
+
+// setMessage sets the message of the commit and all fields derived from it.
+func (c *Commit) setMessage(message string) {
+	c.Message = message
+	c.Title, c.Description = splitMessage(message)
+	c.Trailers = parseTrailers(message)
+	c.Conventional = parseConventionalCommit(c.Title, c.Trailers)
+	c.SkipCI = hasSkipDirective(message)
+}
+
+// TrailerValues returns the values of all trailers with the given key. Keys are
+// matched case-insensitive.
+func (c Commit) TrailerValues(key string) []string {
+	values := make([]string, 0)
+
+	for _, trailer := range c.Trailers {
+		if strings.EqualFold(trailer.Key, key) {
+			values = append(values, trailer.Value)
+		}
+	}
+
+	return values
+}
+
+// SignedOffBy returns the authors of the Signed-off-by trailers.
+func (c Commit) SignedOffBy() []Author {
+	return trailerAuthors(c.TrailerValues(TrailerSignedOffBy))
+}
+
+// CoAuthors returns the authors of the Co-authored-by trailers.
+func (c Commit) CoAuthors() []Author {
+	return trailerAuthors(c.TrailerValues(TrailerCoAuthoredBy))
+}
+
+// Refs returns the values of the Refs trailers, e.g. issue numbers.
+func (c Commit) Refs() []string {
+	return c.TrailerValues(TrailerRefs)
+}
+
+// parseConventionalCommit parses the title of a commit message as Conventional
+// Commit header. A breaking change is marked by `!` in the header or by a
+// BREAKING CHANGE trailer. The result is empty if the title does not match.
+func parseConventionalCommit(title string, trailers []Trailer) ConventionalCommit {
+	match := conventionalHeader.FindStringSubmatch(strings.TrimSpace(title))
+	if match == nil {
+		return ConventionalCommit{}
+	}
+
+	cc := ConventionalCommit{
+		Type:     strings.ToLower(match[1]),
+		Scope:    strings.TrimSpace(match[2]),
+		Subject:  strings.TrimSpace(match[4]),
+		Breaking: match[3] != "",
+	}
+
+	for _, trailer := range trailers {
+		if trailer.Key == TrailerBreakingChange {
+			cc.Breaking = true
+		}
+	}
+
+	return cc
+}
+
+// parseTrailers returns the trailers of the last paragraph of a commit message.
+// The paragraph is only treated as trailers if every line is a trailer or the
+// continuation of one. The title is never parsed as trailer.
+func parseTrailers(message string) []Trailer {
+	message = strings.TrimRight(strings.ReplaceAll(message, "\r\n", "\n"), "\n")
+
+	idx := strings.LastIndex(message, "\n\n")
+	if idx < 0 {
+		return nil
+	}
+
+	trailers := make([]Trailer, 0)
+
+	for _, line := range strings.Split(message[idx+2:], "\n") {
+		if line != "" && (line[0] == ' ' || line[0] == '\t') && len(trailers) > 0 {
+			last := &trailers[len(trailers)-1]
+			last.Value += " " + strings.TrimSpace(line)
+
+			continue
+		}
+
+		match := trailerLine.FindStringSubmatch(line)
+		if match == nil {
+			return nil
+		}
+
+		key := match[1]
+		if key == "BREAKING-CHANGE" {
+			key = TrailerBreakingChange
+		}
+
+		trailers = append(trailers, Trailer{Key: key, Value: strings.TrimSpace(match[2] + match[3])})
+	}
+
+	return trailers
+}
+
+// hasSkipDirective reports whether the commit message contains a directive to
+// skip CI, e.g. `[skip ci]`.
+func hasSkipDirective(message string) bool {
+	message = strings.ToLower(message)
+
+	for _, directive := range skipDirectives {
+		if strings.Contains(message, directive) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// trailerAuthors parses trailer values in the form `Name <email>`. Values that
+// are no valid address are used as name.
+func trailerAuthors(values []string) []Author {
+	authors := make([]Author, 0, len(values))
+
+	for _, value := range values {
+		addr, err := mail.ParseAddress(value)
+		if err != nil {
+			authors = append(authors, Author{Name: value})
+
+			continue
+		}
+
+		authors = append(authors, Author{Name: addr.Name, Email: addr.Address})
+	}
+
+	return authors
+}
@@ -0,0 +1,113 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommitSetMessage(t *testing.T) {
+	tests := []struct {
+		name         string
+		message      string
+		conventional ConventionalCommit
+		trailers     []Trailer
+		skipCI       bool
+	}{
+		{
+			name:    "plain message",
+			message: "Update readme\n\nFix typos in the introduction.",
+		},
+		{
+			name:         "conventional commit with scope",
+			message:      "feat(api): add endpoint",
+			conventional: ConventionalCommit{Type: "feat", Scope: "api", Subject: "add endpoint"},
+		},
+		{
+			name:         "breaking change marker",
+			message:      "Fix!: drop support for v1",
+			conventional: ConventionalCommit{Type: "fix", Subject: "drop support for v1", Breaking: true},
+		},
+		{
+			name:         "breaking change trailer",
+			message:      "refactor: rename option\n\nBREAKING-CHANGE: the option foo is now bar",
+			conventional: ConventionalCommit{Type: "refactor", Subject: "rename option", Breaking: true},
+			trailers:     []Trailer{{Key: "BREAKING CHANGE", Value: "the option foo is now bar"}},
+		},
+		{
+			name: "trailers",
+			message: "fix: handle empty input\n\nLonger description.\n\n" +
+				"Signed-off-by: Octo Cat <octocat@example.com>\n" +
+				"Co-authored-by: Hubot <hubot@example.com>\n" +
+				"Refs #42\n" +
+				"Reviewed-by: A long name\n  continued\n",
+			conventional: ConventionalCommit{Type: "fix", Subject: "handle empty input"},
+			trailers: []Trailer{
+				{Key: "Signed-off-by", Value: "Octo Cat <octocat@example.com>"},
+				{Key: "Co-authored-by", Value: "Hubot <hubot@example.com>"},
+				{Key: "Refs", Value: "#42"},
+				{Key: "Reviewed-by", Value: "A long name continued"},
+			},
+		},
+		{
+			name:    "last paragraph is no trailer block",
+			message: "Update readme\n\nSigned-off-by: Octo Cat <octocat@example.com>\nand some text",
+		},
+		{
+			name:         "title is never a trailer",
+			message:      "Refs: something",
+			conventional: ConventionalCommit{Type: "refs", Subject: "something"},
+		},
+		{
+			name:         "skip directive",
+			message:      "docs: fix typo [SKIP CI]",
+			conventional: ConventionalCommit{Type: "docs", Subject: "fix typo [SKIP CI]"},
+			skipCI:       true,
+		},
+		{
+			name:    "skip directive in description",
+			message: "Update readme\n\n[ci skip]",
+			skipCI:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Commit
+
+			got.setMessage(tt.message)
+
+			title, description := splitMessage(tt.message)
+
+			assert.Equal(t, tt.message, got.Message)
+			assert.Equal(t, title, got.Title)
+			assert.Equal(t, description, got.Description)
+			assert.Equal(t, tt.conventional, got.Conventional)
+			assert.Equal(t, tt.skipCI, got.SkipCI)
+
+			if tt.trailers == nil {
+				assert.Empty(t, got.Trailers)
+
+				return
+			}
+
+			assert.Equal(t, tt.trailers, got.Trailers)
+		})
+	}
+}
+
+func TestCommitTrailerHelpers(t *testing.T) {
+	var c Commit
+
+	c.setMessage("feat: add feature\n\n" +
+		"signed-off-by: Octo Cat <octocat@example.com>\n" +
+		"Co-authored-by: Hubot <hubot@example.com>\n" +
+		"Co-authored-by: unknown\n" +
+		"Refs: #1\n" +
+		"Refs: #2")
+
+	assert.Equal(t, []Author{{Name: "Octo Cat", Email: "octocat@example.com"}}, c.SignedOffBy())
+	assert.Equal(t, []Author{{Name: "Hubot", Email: "hubot@example.com"}, {Name: "unknown"}}, c.CoAuthors())
+	assert.Equal(t, []string{"#1", "#2"}, c.Refs())
+	assert.Empty(t, c.TrailerValues("Reviewed-by"))
+}
@@ -15,27 +15,197 @@
 package plugin
 
 import (
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/urfave/cli/v3"
 )
 
 // Metadata defines runtime metadata.
 type Metadata struct {
-	Repository Repository
-	Pipeline   Pipeline
-	Curr       Commit
-	Prev       Commit
-	Step       Step
-	System     System
+	Repository   Repository `json:"repository"`
+	Pipeline     Pipeline   `json:"pipeline"`
+	PrevPipeline Pipeline   `json:"prev_pipeline"`
+	Workflow     Workflow   `json:"workflow"`
+	Workspace    Workspace  `json:"workspace"`
+	Curr         Commit     `json:"curr"`
+	Prev         Commit     `json:"prev"`
+	Step         Step       `json:"step"`
+	System       System     `json:"system"`
+	Forge        Forge      `json:"forge"`
 }
 
 // MetadataFromContext creates a Metadata from the cli.Command.
 func MetadataFromContext(cmd *cli.Command) Metadata {
 	return Metadata{
-		Repository: repositoryFromContext(cmd),
-		Pipeline:   pipelineFromContext(cmd),
-		Curr:       currFromContext(cmd),
-		Prev:       prevFromContext(cmd),
-		Step:       stepFromContext(cmd),
-		System:     systemFromContext(cmd),
+		Repository:   repositoryFromContext(cmd),
+		Pipeline:     pipelineFromContext(cmd),
+		PrevPipeline: prevPipelineFromContext(cmd),
+		Workflow:     workflowFromContext(cmd),
+		Workspace:    workspaceFromContext(cmd),
+		Curr:         currFromContext(cmd),
+		Prev:         prevFromContext(cmd),
+		Step:         stepFromContext(cmd),
+		System:       systemFromContext(cmd),
+		Forge:        forgeFromContext(cmd),
+	}
+}
+
+// Environ returns the metadata as environment variables in the form
+// "key=value", sorted by key. It contains exactly the variables read by the
+// metadata flags of Flags(), unset values are omitted. The result can be used
+// to pass the metadata to child processes.
+func (m Metadata) Environ() []string {
+	values := m.flagValues()
+	env := make([]string, 0, len(values))
+
+	for _, flag := range metadataFlags() {
+		value := values[flag.Names()[0]]
+		if value == "" {
+			continue
+		}
+
+		docFlag, ok := flag.(cli.DocGenerationFlag)
+		if !ok || len(docFlag.GetEnvVars()) == 0 {
+			continue
+		}
+
+		env = append(env, docFlag.GetEnvVars()[0]+"="+value)
+	}
+
+	slices.Sort(env)
+
+	return env
+}
+
+// flagValues returns the metadata values formatted as flag values and keyed by
+// flag name. It is the inverse of MetadataFromContext.
+func (m Metadata) flagValues() map[string]string {
+	files := ""
+
+	if len(m.Pipeline.Files) > 0 {
+		if data, err := json.Marshal(m.Pipeline.Files); err == nil {
+			files = string(data)
+		}
 	}
+
+	return map[string]string{
+		"repo.slug":           m.Repository.Slug,
+		"repo.name":           m.Repository.Name,
+		"repo.owner":          m.Repository.Owner,
+		"repo.url":            m.Repository.URL,
+		"repo.clone-url":      m.Repository.CloneURL,
+		"repo.clone-ssh-url":  m.Repository.CloneSSHURL,
+		"repo.private":        formatBool(m.Repository.Private),
+		"repo.trusted":        formatBool(m.Repository.Trusted),
+		"repo.default-branch": m.Repository.Branch,
+		"repo.remote-id":      formatInt(m.Repository.RemoteID),
+
+		"pipeline.number":        formatInt(m.Pipeline.Number),
+		"pipeline.status":        m.Pipeline.Status,
+		"pipeline.event":         string(m.Pipeline.Event),
+		"pipeline.url":           m.Pipeline.URL,
+		"pipeline.forge-url":     m.Pipeline.ForgeURL,
+		"pipeline.deploy-target": m.Pipeline.DeployTarget,
+		"pipeline.deploy-task":   m.Pipeline.DeployTask,
+		"pipeline.created":       formatUnix(m.Pipeline.Created),
+		"pipeline.started":       formatUnix(m.Pipeline.Started),
+		"pipeline.finished":      formatUnix(m.Pipeline.Finished),
+		"pipeline.parent":        formatInt(m.Pipeline.Parent),
+		"pipeline.files":         files,
+
+		"prev.pipeline.number":        formatInt(m.PrevPipeline.Number),
+		"prev.pipeline.status":        m.PrevPipeline.Status,
+		"prev.pipeline.event":         string(m.PrevPipeline.Event),
+		"prev.pipeline.url":           m.PrevPipeline.URL,
+		"prev.pipeline.forge-url":     m.PrevPipeline.ForgeURL,
+		"prev.pipeline.deploy-target": m.PrevPipeline.DeployTarget,
+		"prev.pipeline.deploy-task":   m.PrevPipeline.DeployTask,
+		"prev.pipeline.created":       formatUnix(m.PrevPipeline.Created),
+		"prev.pipeline.started":       formatUnix(m.PrevPipeline.Started),
+		"prev.pipeline.finished":      formatUnix(m.PrevPipeline.Finished),
+		"prev.pipeline.parent":        formatInt(m.PrevPipeline.Parent),
+
+		"workflow.name":   m.Workflow.Name,
+		"workflow.number": formatInt(m.Workflow.Number),
+		"workspace.path":  m.Workspace.Path,
+
+		"commit.url":                    m.Curr.URL,
+		"commit.sha":                    m.Curr.SHA,
+		"commit.ref":                    m.Curr.Ref,
+		"commit.refspec":                m.Curr.Refspec,
+		"commit.pull-request":           formatInt(m.Curr.PullRequest),
+		"commit.pull-request.labels":    strings.Join(m.Curr.PullRequestLabels, ","),
+		"commit.pull-request.milestone": m.Curr.PullRequestMilestone,
+		"commit.pull-request.from-fork": formatBool(m.Curr.PullRequestFromFork),
+		"commit.source-branch":          m.Curr.SourceBranch,
+		"commit.target-branch":          m.Curr.TargetBranch,
+		"commit.branch":                 m.Curr.Branch,
+		"commit.tag":                    m.Curr.Tag,
+		"commit.prerelease":             formatBool(m.Curr.Prerelease),
+		"commit.message":                m.Curr.Message,
+		"commit.author.name":            m.Curr.Author.Name,
+		"commit.author.email":           m.Curr.Author.Email,
+		"commit.author.avatar":          m.Curr.Author.Avatar,
+
+		"prev.commit.url":                    m.Prev.URL,
+		"prev.commit.sha":                    m.Prev.SHA,
+		"prev.commit.ref":                    m.Prev.Ref,
+		"prev.commit.refspec":                m.Prev.Refspec,
+		"prev.commit.pull-request":           formatInt(m.Prev.PullRequest),
+		"prev.commit.pull-request.labels":    strings.Join(m.Prev.PullRequestLabels, ","),
+		"prev.commit.pull-request.milestone": m.Prev.PullRequestMilestone,
+		"prev.commit.pull-request.from-fork": formatBool(m.Prev.PullRequestFromFork),
+		"prev.commit.source-branch":          m.Prev.SourceBranch,
+		"prev.commit.target-branch":          m.Prev.TargetBranch,
+		"prev.commit.branch":                 m.Prev.Branch,
+		"prev.commit.tag":                    m.Prev.Tag,
+		"prev.commit.prerelease":             formatBool(m.Prev.Prerelease),
+		"prev.commit.message":                m.Prev.Message,
+		"prev.commit.author.name":            m.Prev.Author.Name,
+		"prev.commit.author.email":           m.Prev.Author.Email,
+		"prev.commit.author.avatar":          m.Prev.Author.Avatar,
+
+		"step.name":     m.Step.Name,
+		"step.number":   formatInt(m.Step.Number),
+		"step.url":      m.Step.URL,
+		"step.started":  formatUnix(m.Step.Started),
+		"step.finished": formatUnix(m.Step.Finished),
+
+		"system.name":    m.System.Name,
+		"system.host":    m.System.Host,
+		"system.url":     m.System.URL,
+		"system.arch":    m.System.Platform,
+		"system.version": m.System.Version,
+
+		"forge.type": m.Forge.Type,
+		"forge.url":  m.Forge.URL,
+	}
+}
+
+func formatInt(value int64) string {
+	if value == 0 {
+		return ""
+	}
+
+	return strconv.FormatInt(value, 10)
+}
+
+func formatBool(value bool) string {
+	if !value {
+		return ""
+	}
+
+	return strconv.FormatBool(value)
+}
+
+func formatUnix(value time.Time) string {
+	if value.IsZero() || value.Unix() == 0 {
+		return ""
+	}
+
+	return strconv.FormatInt(value.Unix(), 10)
 }
@@ -0,0 +1,277 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+)
+
+// fromEnv runs the plugin with the environment variables and returns the
+// result of fn for the parsed flags.
+//
+//nolint:ireturn
+func fromEnv[T any](t *testing.T, envs map[string]string, fn func(*cli.Command) (T, error)) (T, error) {
+	t.Helper()
+
+	for key, value := range envs {
+		t.Setenv(key, value)
+	}
+
+	var (
+		got T
+		err error
+	)
+
+	p := New(Options{Name: "dummy"})
+	p.App.Action = func(_ context.Context, cmd *cli.Command) error {
+		got, err = fn(cmd)
+
+		return nil
+	}
+
+	require.NoError(t, p.App.Run(t.Context(), []string{"dummy"}))
+
+	return got, err
+}
+
+func metadataFromContext(cmd *cli.Command) (Metadata, error) {
+	return MetadataFromContext(cmd), nil
+}
+
+func TestMetadataFromContext(t *testing.T) {
+	got, err := fromEnv(t, map[string]string{
+		"CI_WORKFLOW_NAME":                 "release",
+		"CI_WORKFLOW_NUMBER":               "2",
+		"CI_WORKSPACE":                     "/woodpecker/src/example.com/octocat/hello-world",
+		"CI_STEP_NAME":                     "publish",
+		"CI_STEP_URL":                      "https://ci.example.com/repos/1/pipeline/42/3",
+		"CI_FORGE_TYPE":                    "gitea",
+		"CI_FORGE_URL":                     "https://example.com",
+		"CI_REPO_CLONE_SSH_URL":            "git@example.com:octocat/hello-world.git",
+		"CI_REPO_TRUSTED":                  "true",
+		"CI_COMMIT_PULL_REQUEST_LABELS":    "bug,security",
+		"CI_COMMIT_PULL_REQUEST_MILESTONE": "v1.0",
+		"CI_COMMIT_PRERELEASE":             "true",
+		"CI_PIPELINE_FORGE_URL":            "https://example.com/octocat/hello-world/commit/abc",
+		"CI_PIPELINE_DEPLOY_TASK":          "migrate",
+		"CI_PIPELINE_FILES":                `["README.md","docs/index.md"]`,
+		"CI_PREV_PIPELINE_NUMBER":          "41",
+		"CI_PREV_PIPELINE_STATUS":          "failure",
+		"CI_PREV_PIPELINE_EVENT":           "push",
+		"CI_PREV_PIPELINE_FINISHED":        "1700000000",
+	}, metadataFromContext)
+	require.NoError(t, err)
+
+	assert.Equal(t, Workflow{Name: "release", Number: 2}, got.Workflow)
+	assert.Equal(t, Workspace{Path: "/woodpecker/src/example.com/octocat/hello-world"}, got.Workspace)
+	assert.Equal(t, "publish", got.Step.Name)
+	assert.Equal(t, "https://ci.example.com/repos/1/pipeline/42/3", got.Step.URL)
+	assert.Equal(t, Forge{Type: "gitea", URL: "https://example.com"}, got.Forge)
+	assert.Equal(t, "git@example.com:octocat/hello-world.git", got.Repository.CloneSSHURL)
+	assert.True(t, got.Repository.Trusted)
+	assert.Equal(t, []string{"bug", "security"}, got.Curr.PullRequestLabels)
+	assert.Equal(t, "v1.0", got.Curr.PullRequestMilestone)
+	assert.True(t, got.Curr.Prerelease)
+	assert.Equal(t, "https://example.com/octocat/hello-world/commit/abc", got.Pipeline.ForgeURL)
+	assert.Equal(t, "migrate", got.Pipeline.DeployTask)
+	assert.Equal(t, []string{"README.md", "docs/index.md"}, got.Pipeline.Files)
+	assert.Equal(t, int64(41), got.PrevPipeline.Number)
+	assert.Equal(t, "failure", got.PrevPipeline.Status)
+	assert.Equal(t, EventPush, got.PrevPipeline.Event)
+	assert.Equal(t, time.Unix(1700000000, 0), got.PrevPipeline.Finished)
+}
+
+func TestPipelineFiles(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  []string
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:  "list",
+			value: `["a.go","dir/b.go"]`,
+			want:  []string{"a.go", "dir/b.go"},
+		},
+		{
+			name:  "invalid json",
+			value: "a.go,b.go",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, pipelineFiles(tt.value))
+		})
+	}
+}
+
+func testMetadata() Metadata {
+	return Metadata{
+		Repository: Repository{
+			Slug:        "octocat/hello-world",
+			Name:        "hello-world",
+			Owner:       "octocat",
+			URL:         "https://example.com/octocat/hello-world",
+			CloneURL:    "https://example.com/octocat/hello-world.git",
+			CloneSSHURL: "git@example.com:octocat/hello-world.git",
+			Private:     true,
+			Trusted:     true,
+			Branch:      "main",
+			RemoteID:    123,
+		},
+		Pipeline: Pipeline{
+			Number:       42,
+			Status:       "success",
+			Event:        EventPullRequest,
+			URL:          "https://ci.example.com/repos/1/pipeline/42",
+			ForgeURL:     "https://example.com/octocat/hello-world/pulls/7",
+			DeployTarget: "production",
+			DeployTask:   "migrate",
+			Created:      time.Unix(1700000000, 0),
+			Started:      time.Unix(1700000010, 0),
+			Finished:     time.Unix(1700000100, 0),
+			Parent:       41,
+			Files:        []string{"README.md", "docs/a b.md"},
+		},
+		PrevPipeline: Pipeline{
+			Number:       41,
+			Status:       "failure",
+			Event:        EventPush,
+			URL:          "https://ci.example.com/repos/1/pipeline/41",
+			ForgeURL:     "https://example.com/octocat/hello-world/commit/abc",
+			DeployTarget: "staging",
+			DeployTask:   "seed",
+			Created:      time.Unix(1690000000, 0),
+			Started:      time.Unix(1690000010, 0),
+			Finished:     time.Unix(1690000100, 0),
+			Parent:       40,
+		},
+		Workflow:  Workflow{Name: "release", Number: 2},
+		Workspace: Workspace{Path: "/woodpecker/src"},
+		Curr: Commit{
+			URL:                  "https://example.com/octocat/hello-world/commit/def",
+			SHA:                  "def",
+			Ref:                  "refs/pull/7/head",
+			Refspec:              "feature:main",
+			PullRequest:          7,
+			PullRequestLabels:    []string{"bug", "security"},
+			PullRequestMilestone: "v1.0",
+			PullRequestFromFork:  true,
+			SourceBranch:         "feature",
+			TargetBranch:         "main",
+			Branch:               "main",
+			Tag:                  "v1.0.0",
+			Prerelease:           true,
+			Message:              "feat: add feature\n\nlonger description",
+			Title:                "feat: add feature",
+			Description:          "\nlonger description",
+			Conventional:         ConventionalCommit{Type: "feat", Subject: "add feature"},
+			Author:               Author{Name: "octocat", Email: "octocat@example.com", Avatar: "https://example.com/a.png"},
+		},
+		Prev: Commit{
+			URL:                  "https://example.com/octocat/hello-world/commit/abc",
+			SHA:                  "abc",
+			Ref:                  "refs/pull/6/head",
+			Refspec:              "fix:main",
+			PullRequest:          6,
+			PullRequestLabels:    []string{"bug"},
+			PullRequestMilestone: "v0.9",
+			PullRequestFromFork:  true,
+			SourceBranch:         "fix",
+			TargetBranch:         "main",
+			Branch:               "main",
+			Tag:                  "v0.9.0",
+			Prerelease:           true,
+			Message:              "fix: previous change",
+			Title:                "fix: previous change",
+			Conventional:         ConventionalCommit{Type: "fix", Subject: "previous change"},
+			Author:               Author{Name: "hubot", Email: "hubot@example.com", Avatar: "https://example.com/b.png"},
+		},
+		Step: Step{
+			Name:     "publish",
+			Number:   3,
+			URL:      "https://ci.example.com/repos/1/pipeline/42/3",
+			Started:  time.Unix(1700000020, 0),
+			Finished: time.Unix(1700000090, 0),
+		},
+		System: System{
+			Name:     "woodpecker",
+			Host:     "ci.example.com",
+			URL:      "https://ci.example.com",
+			Platform: "linux/amd64",
+			Version:  "3.0.0",
+		},
+		Forge: Forge{Type: "gitea", URL: "https://example.com"},
+	}
+}
+
+func TestMetadataEnvironRoundTrip(t *testing.T) {
+	want := testMetadata()
+	env := want.Environ()
+
+	envs := make(map[string]string, len(env))
+
+	for _, kv := range env {
+		key, value, ok := strings.Cut(kv, "=")
+		assert.True(t, ok, kv)
+
+		envs[key] = value
+	}
+
+	assert.Len(t, env, len(metadataFlags()))
+	assert.True(t, slices.IsSorted(env))
+	assert.Equal(t, `["README.md","docs/a b.md"]`, envs["CI_PIPELINE_FILES"])
+	assert.Equal(t, "bug,security", envs["CI_COMMIT_PULL_REQUEST_LABELS"])
+
+	got, err := fromEnv(t, envs, metadataFromContext)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestMetadataEnvironOmitsUnset(t *testing.T) {
+	got := Metadata{Repository: Repository{Slug: "octocat/hello-world"}}.Environ()
+
+	assert.Equal(t, []string{"CI_REPO=octocat/hello-world"}, got)
+}
+
+func TestMetadataFlagValues(t *testing.T) {
+	values := Metadata{}.flagValues()
+	names := make([]string, 0, len(values))
+
+	for _, flag := range metadataFlags() {
+		names = append(names, flag.Names()[0])
+	}
+
+	for name := range values {
+		assert.Contains(t, names, name)
+	}
+
+	assert.Len(t, values, len(names))
+}
+
+func TestMetadataJSON(t *testing.T) {
+	want := testMetadata()
+
+	data, err := json.Marshal(want)
+	assert.NoError(t, err)
+
+	var got Metadata
+
+	assert.NoError(t, json.Unmarshal(data, &got))
+
+	assert.Equal(t, EventPullRequest, got.Pipeline.Event)
+	assert.Equal(t, want.Pipeline.Files, got.Pipeline.Files)
+	assert.True(t, want.Pipeline.Created.Equal(got.Pipeline.Created))
+	assert.Equal(t, want.Environ(), got.Environ())
+	assert.Contains(t, string(data), `"prev_pipeline":{"number":41`)
+}
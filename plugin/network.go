@@ -17,7 +17,8 @@ package plugin
 import (
 	"context"
 	"crypto/tls"
-	"crypto/x509"
+	"errors"
+	"fmt"
 	"net"
 	"net/http"
 	"time"
@@ -26,7 +27,6 @@ import (
 	"github.com/rs/zerolog/log"
 	plugin_trace "github.com/thegeeklab/wp-plugin-go/v6/trace"
 	"github.com/urfave/cli/v3"
-	"golang.org/x/net/proxy"
 )
 
 const (
@@ -36,6 +36,8 @@ const (
 	HTTPTransportMaxIdleConns        = 100
 )
 
+var ErrDryRunRequest = errors.New("request refused in dry-run mode")
+
 // Network contains options for connecting to the network.
 type Network struct {
 	// Context for making network requests.
@@ -48,54 +50,99 @@ type Network struct {
 	/// Whether SSL verification is skipped or not.
 	InsecureSkipVerify bool
 
+	// TLS configuration used by the client, including the additional CA
+	// certificates and client certificates.
+	TLSConfig *tls.Config
+
 	// Client for making network requests.
 	Client *http.Client
 }
 
 func networkFlags(category string) []cli.Flag {
-	return []cli.Flag{
+	flags := []cli.Flag{
 		&cli.BoolFlag{
 			Name:     "transport.insecure-skip-verify",
 			Usage:    "skip SSL verification",
 			Sources:  cli.EnvVars("PLUGIN_INSECURE_SKIP_VERIFY"),
 			Category: category,
 		},
-		&cli.StringFlag{
-			Name:    "transport.socks-proxy",
-			Usage:   "socks proxy address",
-			Sources: cli.EnvVars("SOCKS_PROXY"),
-			Hidden:  true,
-		},
 		&cli.BoolFlag{
-			Name:    "transport.socks-proxy-off",
-			Usage:   "socks proxy ignored",
-			Sources: cli.EnvVars("SOCKS_PROXY_OFF"),
-			Hidden:  true,
+			Name:     "transport.dry-run-read-only",
+			Usage:    "refuse all network requests except GET, HEAD and OPTIONS in dry-run mode",
+			Sources:  cli.EnvVars("PLUGIN_DRY_RUN_READ_ONLY"),
+			Category: category,
+		},
+		&cli.IntFlag{
+			Name:     "transport.retry-max-attempts",
+			Usage:    "maximum number of attempts for failed idempotent network requests, 1 disables retries",
+			Value:    RetryMaxAttempts,
+			Sources:  cli.EnvVars("PLUGIN_RETRY_MAX_ATTEMPTS"),
+			Category: category,
+		},
+		&cli.DurationFlag{
+			Name:     "transport.retry-max-time",
+			Usage:    "maximum time spent on a network request including retries",
+			Value:    RetryMaxTime,
+			Sources:  cli.EnvVars("PLUGIN_RETRY_MAX_TIME"),
+			Category: category,
+		},
+		&cli.DurationFlag{
+			Name:     "transport.retry-delay",
+			Usage:    "initial delay between retries, doubled on each retry",
+			Value:    RetryDelay,
+			Sources:  cli.EnvVars("PLUGIN_RETRY_DELAY"),
+			Category: category,
+		},
+		&cli.DurationFlag{
+			Name:     "transport.retry-max-delay",
+			Usage:    "maximum delay between retries",
+			Value:    RetryMaxDelay,
+			Sources:  cli.EnvVars("PLUGIN_RETRY_MAX_DELAY"),
+			Category: category,
 		},
 	}
+
+	flags = append(flags, proxyFlags(category)...)
+
+	return append(flags, tlsFlags(category)...)
 }
 
+// NetworkFromContext creates the network options of the flags. Invalid proxy
+// or TLS flags exit the process with a fatal log message, the configured proxy
+// and certificates are never silently replaced by less restrictive defaults.
+//
+// Deprecated: Use NewNetwork, which returns invalid flags as error.
 func NetworkFromContext(cmd *cli.Command) Network {
-	var (
-		skipVerify     = cmd.Bool("transport.insecure-skip-verify")
-		defaultContext = context.Background()
-		socks          = cmd.String("transport.socks-proxy")
-		socksoff       = cmd.Bool("transport.socks-proxy-off")
-	)
+	network, err := NewNetwork(cmd)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid network flags")
+	}
 
-	certs, err := x509.SystemCertPool()
+	return network
+}
+
+// NewNetwork creates the network options of the flags. It fails if the proxy
+// or TLS flags are invalid.
+func NewNetwork(cmd *cli.Command) (Network, error) {
+	tlsConfig, err := tlsConfigFromContext(cmd)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to find system CA certs")
+		return Network{}, err
 	}
 
-	tlsConfig := &tls.Config{
-		RootCAs:            certs,
-		InsecureSkipVerify: skipVerify, //nolint:gosec
+	proxyConfig, err := proxyConfigFromContext(cmd)
+	if err != nil {
+		return Network{}, err
 	}
 
+	var (
+		skipVerify     = cmd.Bool("transport.insecure-skip-verify")
+		defaultContext = context.Background()
+		readOnly       = cmd.Bool("dry-run") && cmd.Bool("transport.dry-run-read-only")
+	)
+
 	transport := &http.Transport{
 		TLSClientConfig:       tlsConfig,
-		Proxy:                 http.ProxyFromEnvironment,
+		Proxy:                 proxyConfig.Proxy,
 		MaxIdleConns:          HTTPTransportMaxIdleConns,
 		IdleConnTimeout:       HTTPTransportIdleTimeout,
 		TLSHandshakeTimeout:   HTTPTransportTLSHandshakeTimeout,
@@ -108,34 +155,54 @@ func NetworkFromContext(cmd *cli.Command) Network {
 		DualStack: true,
 	}
 
-	if len(socks) != 0 && !socksoff {
-		proxyDialer, err := proxy.SOCKS5("tcp", socks, nil, dialer)
-		if err != nil {
-			log.Error().Err(err).Msg("failed to create socks proxy")
-		}
-
-		if contextDialer, ok := proxyDialer.(proxy.ContextDialer); ok {
-			transport.DialContext = contextDialer.DialContext
-		} else {
-			transport.DialContext = func(_ context.Context, network, addr string) (net.Conn, error) {
-				return proxyDialer.Dial(network, addr)
-			}
-		}
-	} else {
-		transport.DialContext = dialer.DialContext
-	}
+	transport.DialContext = dialer.DialContext
 
 	if zerolog.GlobalLevel() == zerolog.TraceLevel {
 		defaultContext = plugin_trace.HTTP(defaultContext)
 	}
 
 	client := &http.Client{
-		Transport: transport,
+		Transport: &retryTransport{
+			next:        transport,
+			maxAttempts: cmd.Int("transport.retry-max-attempts"),
+			maxTime:     cmd.Duration("transport.retry-max-time"),
+			delay:       cmd.Duration("transport.retry-delay"),
+			maxDelay:    cmd.Duration("transport.retry-max-delay"),
+		},
+	}
+
+	if readOnly {
+		client.Transport = &readOnlyTransport{next: client.Transport}
 	}
 
 	return Network{
 		Context:            defaultContext,
 		InsecureSkipVerify: skipVerify,
+		TLSConfig:          tlsConfig,
 		Client:             client,
+	}, nil
+}
+
+// readOnlyTransport is a http.RoundTripper that refuses all requests with
+// methods that might change the state of the remote.
+type readOnlyTransport struct {
+	next http.RoundTripper
+}
+
+func (t *readOnlyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions:
+		return t.next.RoundTrip(req)
+	}
+
+	if req.Body != nil {
+		_ = req.Body.Close()
 	}
+
+	log.Info().
+		Str("method", req.Method).
+		Str("url", req.URL.Redacted()).
+		Msg("dry-run: refuse network request")
+
+	return nil, fmt.Errorf("%w: %s %s", ErrDryRunRequest, req.Method, req.URL.Redacted())
 }
@@ -0,0 +1,73 @@
+package plugin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+)
+
+func TestReadOnlyTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := &http.Client{
+		Transport: &readOnlyTransport{next: http.DefaultTransport},
+	}
+
+	tests := []struct {
+		name    string
+		method  string
+		wantErr bool
+	}{
+		{name: "get", method: http.MethodGet},
+		{name: "head", method: http.MethodHead},
+		{name: "post", method: http.MethodPost, wantErr: true},
+		{name: "delete", method: http.MethodDelete, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(t.Context(), tt.method, server.URL, strings.NewReader("body"))
+			assert.NoError(t, err)
+
+			res, err := client.Do(req)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrDryRunRequest)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+			res.Body.Close()
+		})
+	}
+}
+
+func TestNetworkFromContextWithoutFlags(t *testing.T) {
+	var network Network
+
+	cmd := &cli.Command{
+		Name:  "dummy",
+		Flags: []cli.Flag{&cli.BoolFlag{Name: "transport.insecure-skip-verify"}},
+		Action: func(_ context.Context, cmd *cli.Command) error {
+			network = NetworkFromContext(cmd) //nolint:staticcheck
+
+			return nil
+		},
+	}
+
+	require.NoError(t, cmd.Run(t.Context(), []string{"dummy", "--transport.insecure-skip-verify"}))
+
+	require.NotNil(t, network.Client)
+	assert.True(t, network.InsecureSkipVerify)
+	assert.True(t, network.TLSConfig.InsecureSkipVerify)
+}
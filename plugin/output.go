@@ -0,0 +1,153 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/joho/godotenv"
+	"github.com/thegeeklab/wp-plugin-go/v6/file"
+	"github.com/urfave/cli/v3"
+)
+
+const (
+	OutputFormatDotenv = "dotenv"
+	OutputFormatJSON   = "json"
+)
+
+var ErrUnsupportedOutputFormat = errors.New("unsupported output format")
+
+// Outputs collects the step outputs of the plugin that are passed to
+// subsequent steps. It is safe for concurrent use.
+type Outputs struct {
+	mu     sync.Mutex
+	values map[string]string
+	json   map[string]bool
+}
+
+// NewOutputs creates an empty output collection.
+func NewOutputs() *Outputs {
+	return &Outputs{
+		values: make(map[string]string),
+		json:   make(map[string]bool),
+	}
+}
+
+func outputFlags(category string) []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:     "output-file",
+			Usage:    "path to write the step outputs to",
+			Sources:  cli.EnvVars("PLUGIN_OUTPUT_FILE"),
+			Category: category,
+		},
+		&cli.StringFlag{
+			Name:     "output-format",
+			Usage:    "format of the output file, either `dotenv` or `json`; detected from the file extension if unset",
+			Sources:  cli.EnvVars("PLUGIN_OUTPUT_FORMAT"),
+			Category: category,
+		},
+	}
+}
+
+// Set sets the output with the given key to a string value.
+func (o *Outputs) Set(key, value string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.values[key] = value
+	delete(o.json, key)
+}
+
+// SetJSON sets the output with the given key to the JSON encoding of v. In
+// dotenv files the value is written as JSON string, in JSON files it is
+// embedded as JSON value.
+func (o *Outputs) SetJSON(key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode output %s: %w", key, err)
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.values[key] = string(data)
+	o.json[key] = true
+
+	return nil
+}
+
+// Get returns the value of the output with the given key.
+func (o *Outputs) Get(key string) (string, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	value, ok := o.values[key]
+
+	return value, ok
+}
+
+// Values returns a copy of all outputs.
+func (o *Outputs) Values() map[string]string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return maps.Clone(o.values)
+}
+
+// Marshal encodes the outputs in the given format.
+func (o *Outputs) Marshal(format string) ([]byte, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	switch format {
+	case OutputFormatDotenv:
+		content, err := godotenv.Marshal(o.values)
+		if err != nil {
+			return nil, err
+		}
+
+		return []byte(content + "\n"), nil
+	case OutputFormatJSON:
+		values := make(map[string]any, len(o.values))
+
+		for key, value := range o.values {
+			values[key] = value
+			if o.json[key] {
+				values[key] = json.RawMessage(value)
+			}
+		}
+
+		data, err := json.MarshalIndent(values, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+
+		return append(data, '\n'), nil
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrUnsupportedOutputFormat, format)
+}
+
+// WriteFile atomically writes the outputs to the given path. If format is
+// empty, it is detected from the file extension.
+func (o *Outputs) WriteFile(path, format string) error {
+	if format == "" {
+		format = OutputFormatDotenv
+		if strings.EqualFold(filepath.Ext(path), ".json") {
+			format = OutputFormatJSON
+		}
+	}
+
+	data, err := o.Marshal(format)
+	if err != nil {
+		return err
+	}
+
+	//nolint:mnd
+	return file.WriteFileAtomic(path, data, 0o644)
+}
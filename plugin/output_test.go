@@ -0,0 +1,137 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutputsMarshal(t *testing.T) {
+	outputs := NewOutputs()
+	outputs.Set("version", "1.2.3")
+	outputs.Set("notes", "line one\nline \"two\"")
+	assert.NoError(t, outputs.SetJSON("tags", []string{"latest", "1.2"}))
+
+	tests := []struct {
+		name    string
+		format  string
+		want    string
+		wantErr error
+	}{
+		{
+			name:   "dotenv",
+			format: OutputFormatDotenv,
+			want:   "notes=\"line one\\nline \\\"two\\\"\"\ntags=\"[\\\"latest\\\",\\\"1.2\\\"]\"\nversion=\"1.2.3\"\n",
+		},
+		{
+			name:   "json",
+			format: OutputFormatJSON,
+			want: `{
+  "notes": "line one\nline \"two\"",
+  "tags": [
+    "latest",
+    "1.2"
+  ],
+  "version": "1.2.3"
+}
+`,
+		},
+		{
+			name:    "unsupported format",
+			format:  "xml",
+			wantErr: ErrUnsupportedOutputFormat,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := outputs.Marshal(tt.format)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestOutputsDotenvRoundTrip(t *testing.T) {
+	outputs := NewOutputs()
+	outputs.Set("notes", "line one\nline \"two\" with $HOME")
+	outputs.Set("empty", "")
+
+	path := filepath.Join(t.TempDir(), "outputs.env")
+	assert.NoError(t, outputs.WriteFile(path, ""))
+
+	got, err := godotenv.Read(path)
+	assert.NoError(t, err)
+	assert.Equal(t, outputs.Values(), got)
+}
+
+func TestPluginOutputFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		execErr error
+		want    string
+	}{
+		{
+			name: "detect json",
+			file: "outputs.json",
+			want: "{\n  \"version\": \"1.2.3\"\n}\n",
+		},
+		{
+			name: "default dotenv",
+			file: "outputs",
+			want: "version=\"1.2.3\"\n",
+		},
+		{
+			name:    "skipped",
+			file:    "outputs",
+			execErr: Skip("nothing to do"),
+			want:    "version=\"1.2.3\"\n",
+		},
+		{
+			name:    "failure",
+			file:    "outputs",
+			execErr: errDummy,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			t.Setenv("PLUGIN_OUTPUT_FILE", path)
+
+			var p *Plugin
+
+			p = New(Options{
+				Name: "dummy",
+				Execute: func(_ context.Context) error {
+					p.Outputs.Set("version", "1.2.3")
+
+					return tt.execErr
+				},
+			})
+
+			_ = p.App.Run(t.Context(), []string{"dummy"})
+
+			data, err := os.ReadFile(path)
+			if tt.want == "" {
+				assert.ErrorIs(t, err, os.ErrNotExist)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(data))
+		})
+	}
+}
@@ -15,22 +15,29 @@
 package plugin
 
 import (
+	"encoding/json"
 	"time"
 
+	"github.com/rs/zerolog/log"
 	"github.com/urfave/cli/v3"
 )
 
 // Pipeline defines runtime metadata for a pipeline.
 type Pipeline struct {
-	Number       int64
-	Status       string
-	Event        string
-	URL          string
-	DeployTarget string
-	Created      time.Time
-	Started      time.Time
-	Finished     time.Time
-	Parent       int64
+	Number       int64     `json:"number"`
+	Status       string    `json:"status"`
+	Event        Event     `json:"event"`
+	URL          string    `json:"url"`
+	ForgeURL     string    `json:"forge_url"`
+	DeployTarget string    `json:"deploy_target"`
+	DeployTask   string    `json:"deploy_task"`
+	Created      time.Time `json:"created"`
+	Started      time.Time `json:"started"`
+	Finished     time.Time `json:"finished"`
+	Parent       int64     `json:"parent"`
+	// Files changed by the pipeline event. Woodpecker omits the list for events
+	// with too many changed files.
+	Files []string `json:"files"`
 }
 
 func pipelineFlags(category string) []cli.Flag {
@@ -60,12 +67,24 @@ func pipelineFlags(category string) []cli.Flag {
 			Sources:  cli.EnvVars("CI_PIPELINE_URL"),
 			Category: category,
 		},
+		&cli.StringFlag{
+			Name:     "pipeline.forge-url",
+			Usage:    "pipeline forge url",
+			Sources:  cli.EnvVars("CI_PIPELINE_FORGE_URL"),
+			Category: category,
+		},
 		&cli.StringFlag{
 			Name:     "pipeline.deploy-target",
 			Usage:    "pipeline deployment target",
 			Sources:  cli.EnvVars("CI_PIPELINE_DEPLOY_TARGET"),
 			Category: category,
 		},
+		&cli.StringFlag{
+			Name:     "pipeline.deploy-task",
+			Usage:    "pipeline deployment task",
+			Sources:  cli.EnvVars("CI_PIPELINE_DEPLOY_TASK"),
+			Category: category,
+		},
 		&cli.Int64Flag{
 			Name:     "pipeline.created",
 			Usage:    "pipeline creation time",
@@ -90,6 +109,12 @@ func pipelineFlags(category string) []cli.Flag {
 			Sources:  cli.EnvVars("CI_PIPELINE_PARENT"),
 			Category: category,
 		},
+		&cli.StringFlag{
+			Name:     "pipeline.files",
+			Usage:    "pipeline changed files as JSON list",
+			Sources:  cli.EnvVars("CI_PIPELINE_FILES"),
+			Category: category,
+		},
 	}
 }
 
@@ -97,12 +122,134 @@ func pipelineFromContext(c *cli.Command) Pipeline {
 	return Pipeline{
 		Number:       c.Int64("pipeline.number"),
 		Status:       c.String("pipeline.status"),
-		Event:        c.String("pipeline.event"),
+		Event:        pipelineEvent(c.String("pipeline.event")),
 		URL:          c.String("pipeline.url"),
+		ForgeURL:     c.String("pipeline.forge-url"),
 		DeployTarget: c.String("pipeline.deploy-target"),
-		Created:      time.Unix(c.Int64("pipeline.created"), 0),
-		Started:      time.Unix(c.Int64("pipeline.started"), 0),
-		Finished:     time.Unix(c.Int64("pipeline.finished"), 0),
+		DeployTask:   c.String("pipeline.deploy-task"),
+		Created:      unixTime(c.Int64("pipeline.created")),
+		Started:      unixTime(c.Int64("pipeline.started")),
+		Finished:     unixTime(c.Int64("pipeline.finished")),
 		Parent:       c.Int64("pipeline.parent"),
+		Files:        pipelineFiles(c.String("pipeline.files")),
+	}
+}
+
+func prevPipelineFlags(category string) []cli.Flag {
+	return []cli.Flag{
+		&cli.Int64Flag{
+			Name:     "prev.pipeline.number",
+			Usage:    "previous pipeline number",
+			Sources:  cli.EnvVars("CI_PREV_PIPELINE_NUMBER"),
+			Category: category,
+		},
+		&cli.StringFlag{
+			Name:     "prev.pipeline.status",
+			Usage:    "previous pipeline status",
+			Sources:  cli.EnvVars("CI_PREV_PIPELINE_STATUS"),
+			Category: category,
+		},
+		&cli.StringFlag{
+			Name:     "prev.pipeline.event",
+			Usage:    "previous pipeline event",
+			Sources:  cli.EnvVars("CI_PREV_PIPELINE_EVENT"),
+			Category: category,
+		},
+		&cli.StringFlag{
+			Name:     "prev.pipeline.url",
+			Usage:    "previous pipeline url",
+			Sources:  cli.EnvVars("CI_PREV_PIPELINE_URL"),
+			Category: category,
+		},
+		&cli.StringFlag{
+			Name:     "prev.pipeline.forge-url",
+			Usage:    "previous pipeline forge url",
+			Sources:  cli.EnvVars("CI_PREV_PIPELINE_FORGE_URL"),
+			Category: category,
+		},
+		&cli.StringFlag{
+			Name:     "prev.pipeline.deploy-target",
+			Usage:    "previous pipeline deployment target",
+			Sources:  cli.EnvVars("CI_PREV_PIPELINE_DEPLOY_TARGET"),
+			Category: category,
+		},
+		&cli.StringFlag{
+			Name:     "prev.pipeline.deploy-task",
+			Usage:    "previous pipeline deployment task",
+			Sources:  cli.EnvVars("CI_PREV_PIPELINE_DEPLOY_TASK"),
+			Category: category,
+		},
+		&cli.Int64Flag{
+			Name:     "prev.pipeline.created",
+			Usage:    "previous pipeline creation time",
+			Sources:  cli.EnvVars("CI_PREV_PIPELINE_CREATED"),
+			Category: category,
+		},
+		&cli.Int64Flag{
+			Name:     "prev.pipeline.started",
+			Usage:    "previous pipeline start time",
+			Sources:  cli.EnvVars("CI_PREV_PIPELINE_STARTED"),
+			Category: category,
+		},
+		&cli.Int64Flag{
+			Name:     "prev.pipeline.finished",
+			Usage:    "previous pipeline finish time",
+			Sources:  cli.EnvVars("CI_PREV_PIPELINE_FINISHED"),
+			Category: category,
+		},
+		&cli.Int64Flag{
+			Name:     "prev.pipeline.parent",
+			Usage:    "previous pipeline parent",
+			Sources:  cli.EnvVars("CI_PREV_PIPELINE_PARENT"),
+			Category: category,
+		},
+	}
+}
+
+func prevPipelineFromContext(c *cli.Command) Pipeline {
+	return Pipeline{
+		Number:       c.Int64("prev.pipeline.number"),
+		Status:       c.String("prev.pipeline.status"),
+		Event:        pipelineEvent(c.String("prev.pipeline.event")),
+		URL:          c.String("prev.pipeline.url"),
+		ForgeURL:     c.String("prev.pipeline.forge-url"),
+		DeployTarget: c.String("prev.pipeline.deploy-target"),
+		DeployTask:   c.String("prev.pipeline.deploy-task"),
+		Created:      unixTime(c.Int64("prev.pipeline.created")),
+		Started:      unixTime(c.Int64("prev.pipeline.started")),
+		Finished:     unixTime(c.Int64("prev.pipeline.finished")),
+		Parent:       c.Int64("prev.pipeline.parent"),
+	}
+}
+
+// pipelineEvent converts the event name. Unknown events are logged and left
+// unset, the plugin setup fails for an unknown event of the current pipeline.
+func pipelineEvent(value string) Event {
+	event := Event(value)
+
+	if event != "" && !event.Valid() {
+		log.Warn().Str("event", value).Msg("unknown pipeline event")
+
+		return ""
+	}
+
+	return event
+}
+
+// pipelineFiles decodes the JSON list of changed files. An invalid list is
+// logged and ignored.
+func pipelineFiles(value string) []string {
+	if value == "" {
+		return nil
+	}
+
+	var files []string
+
+	if err := json.Unmarshal([]byte(value), &files); err != nil {
+		log.Warn().Err(err).Msg("failed to decode pipeline files")
+
+		return nil
 	}
+
+	return files
 }
@@ -16,14 +16,17 @@ package plugin
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"net/url"
 	"os"
-	"strconv"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/rs/zerolog/log"
+	"github.com/thegeeklab/wp-plugin-go/v6/dryrun"
+	plugin_exec "github.com/thegeeklab/wp-plugin-go/v6/exec"
 	"github.com/urfave/cli/v3"
 )
 
@@ -70,21 +73,66 @@ type Options struct {
 	VersionMetadata string
 	// Flags of the plugin.
 	Flags []cli.Flag
+	// Names of the flags with sensitive values, e.g. tokens or passwords. The
+	// values are redacted from logs, command traces and reports.
+	SecretFlags []string
+	// Rules deciding whether the plugin runs, evaluated before the validate
+	// function. If the rules do not match, the run is skipped. Rules set by the
+	// when flag must match as well.
+	When When
+	// Validate function of the plugin, called first to check the configuration.
+	Validate ExecuteFunc
+	// BeforeExecute function of the plugin, called after validation to prepare
+	// the execution.
+	BeforeExecute ExecuteFunc
 	// Execute function of the plugin.
 	Execute ExecuteFunc
+	// Commands of the plugin. The command to run is selected by the first argument
+	// or the PLUGIN_COMMAND environment variable. If no command is selected, the
+	// execute function of the plugin is used.
+	Commands []Command
+	// AfterExecute function of the plugin, called after a successful execution.
+	AfterExecute ExecuteFunc
+	// OnError function of the plugin, called with the error if any of the
+	// previous functions failed.
+	OnError ErrorFunc
+	// Cleanup function of the plugin, always called last, even if a previous
+	// function failed or panicked.
+	Cleanup ExecuteFunc
+	// Metadata providers checked before the built-in providers when the CI
+	// system is detected or selected by the metadata-provider flag.
+	MetadataProviders []MetadataProvider
 	// Hide woodpecker system flags.
 	HideWoodpeckerFlags bool
+	// Grace period for the execute function to return after a termination
+	// signal was received. Defaults to DefaultShutdownTimeout.
+	ShutdownTimeout time.Duration
 }
 
 // Plugin defines the plugin instance.
 type Plugin struct {
-	App     *cli.Command
-	execute ExecuteFunc
+	App             *cli.Command
+	lifecycle       lifecycle
+	shutdownTimeout time.Duration
+	config          *configFile
+	secretFlags     []string
+	providers       []MetadataProvider
+	when            When
+	// Whether the plugin runs in dry-run mode.
+	DryRun bool
 	// Network options.
 	Network Network
 	// Metadata of the current pipeline.
 	Metadata    Metadata
 	Environment Environment
+	// Outputs of the step, written to the output file after a successful run.
+	Outputs *Outputs
+	// Report of the step, written to the report file and logged at the end of
+	// the run.
+	Report *Report
+	// Secrets redacted from logs, command traces and reports. Values of the
+	// secret flags are registered automatically.
+	Secrets *Secrets
 }
 
 // ExecuteFunc defines the function that is executed by the plugin.
@@ -98,12 +146,29 @@ func New(opt Options) *Plugin {
 
 	_, _ = SetupConsoleLogger(context.Background(), nil)
 
+	flags := slices.Concat(opt.Flags, Flags())
+	secrets := NewSecrets()
+
+	var config *configFile
+
+	if path := os.Getenv(ConfigFileEnv); path != "" {
+		config = newConfigFile(path)
+		addConfigSource(config, flags)
+	}
+
 	app := &cli.Command{
 		Name:    opt.Name,
 		Usage:   opt.Description,
 		Version: opt.Version,
-		Flags:   append(opt.Flags, Flags()...),
-		Before:  SetupConsoleLogger,
+		Flags:   flags,
+		Before: func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+			return setupConsoleLogger(ctx, cmd, secrets.Writer(os.Stdout))
+		},
+		OnUsageError: func(_ context.Context, _ *cli.Command, err error, _ bool) error {
+			return NewError(CategoryUsage, err).WithHint("run with --help to show the usage")
+		},
+		// Exit codes are handled by Run.
+		ExitErrHandler: func(_ context.Context, _ *cli.Command, _ error) {},
 	}
 
 	if opt.HideWoodpeckerFlags {
@@ -116,49 +181,210 @@ func New(opt Options) *Plugin {
 	}
 
 	plugin := &Plugin{
-		App:     app,
-		execute: opt.Execute,
+		App: app,
+		lifecycle: lifecycle{
+			validate: opt.Validate,
+			before:   opt.BeforeExecute,
+			after:    opt.AfterExecute,
+			onError:  opt.OnError,
+			cleanup:  opt.Cleanup,
+		},
+		shutdownTimeout: opt.ShutdownTimeout,
+		config:          config,
+		secretFlags:     slices.Concat(opt.SecretFlags, []string{"transport.proxy-password", "transport.client-key"}),
+		providers:       slices.Concat(opt.MetadataProviders, MetadataProviders()),
+		when:            opt.When,
+		Outputs:         NewOutputs(),
+		Report:          &Report{secrets: secrets},
+		Secrets:         secrets,
+	}
+
+	if plugin.shutdownTimeout <= 0 {
+		plugin.shutdownTimeout = DefaultShutdownTimeout
+	}
+
+	plugin.App.Action = plugin.action(opt.Execute)
+
+	if len(opt.Commands) > 0 {
+		plugin.App.Commands = plugin.commands(opt.Commands)
+		plugin.App.DefaultCommand = os.Getenv(CommandEnv)
+		plugin.App.Action = plugin.defaultAction(opt.Execute)
 	}
-	plugin.App.Action = plugin.action
 
 	return plugin
 }
 
-func (p *Plugin) action(ctx context.Context, cmd *cli.Command) error {
+// action returns the cli action that initializes the plugin and runs the
+// lifecycle with the given execute function if the when rules match. The report
+// is written regardless of the outcome, the step outputs only if the run
+// succeeded or was skipped.
+func (p *Plugin) action(execute ExecuteFunc) cli.ActionFunc {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		if err := p.setup(cmd); err != nil {
+			return err
+		}
+
+		if execute == nil {
+			panic("plugin execute function is not set")
+		}
+
+		l := p.lifecycle
+		l.execute = execute
+
+		err := p.evaluateWhen(cmd)
+		if err == nil {
+			err = l.run(ctx)
+		}
+
+		p.writeReport(ctx, cmd)
+
+		if _, skipped := asSkip(err); err != nil && !skipped {
+			return err
+		}
+
+		if path := cmd.String("output-file"); path != "" {
+			if err := p.Outputs.WriteFile(path, cmd.String("output-format")); err != nil {
+				return NewError(CategoryInternal, fmt.Errorf("failed to write output file: %w", err))
+			}
+		}
+
+		return err
+	}
+}
+
+// writeReport logs the report and writes it to the report file if configured.
+// Failures are logged only, the report must not change the outcome of the run.
+func (p *Plugin) writeReport(ctx context.Context, cmd *cli.Command) {
+	if p.Report.Empty() {
+		return
+	}
+
+	content := p.Report.String()
+
+	if path := cmd.String("report-file"); path != "" {
+		var err error
+
+		content, err = p.Report.WriteFile(ctx, p.Network.Client, path, cmd.String("report-template"), p.Metadata)
+		if err != nil {
+			log.Error().Err(err).Str("path", path).Msg("failed to write report file")
+
+			content = p.Report.String()
+		}
+	}
+
+	log.Info().Msg("step report\n" + strings.TrimRight(content, "\n"))
+}
+
+// setup initializes the metadata, network and environment of the plugin.
+func (p *Plugin) setup(cmd *cli.Command) error {
 	var err error
 
-	p.Metadata = MetadataFromContext(cmd)
-	p.Network = NetworkFromContext(cmd)
+	if p.config != nil {
+		if err := p.config.check(); err != nil {
+			return NewError(CategoryConfig, err).WithHint("check the file set by %s", ConfigFileEnv)
+		}
+	}
 
-	p.Environment, err = EnvironmentFromContext(cmd)
+	p.Secrets.addFlags(cmd, p.secretFlags)
+	p.Secrets.Add(proxyPasswords(cmd)...)
+	plugin_exec.SetRedactFunc(p.Secrets.Redact)
+
+	p.DryRun = cmd.Bool("dry-run")
+	dryrun.Set(p.DryRun)
+
+	if p.DryRun {
+		log.Warn().Msg("dry-run mode enabled, no changes will be made")
+	}
+
+	provider, err := selectMetadataProvider(cmd.String("metadata-provider"), p.providers)
 	if err != nil {
-		return err
+		return NewError(CategoryConfig, err).
+			WithHint("set PLUGIN_METADATA_PROVIDER to one of: %s", metadataProviderNames(p.providers))
 	}
 
-	if p.Metadata.Pipeline.URL == "" {
-		url, err := url.JoinPath(
-			p.Metadata.System.URL,
-			"repos",
-			p.Metadata.Repository.Slug,
-			"pipeline",
-			strconv.FormatInt(p.Metadata.Pipeline.Number, 10),
-		)
-		if err == nil {
-			p.Metadata.Pipeline.URL = url
+	applyMetadataProvider(cmd, provider)
+
+	p.Metadata = MetadataFromContext(cmd)
+
+	if cmd.Bool("git-metadata") {
+		if err := p.Metadata.fillFromGit(p.Metadata.workspaceDir()); err != nil {
+			log.Warn().Err(err).Msg("failed to read metadata from git repository")
 		}
 	}
 
-	if p.execute == nil {
-		panic("plugin execute function is not set")
+	p.Network, err = NewNetwork(cmd)
+	if err != nil {
+		return NewError(CategoryConfig, err).
+			WithHint("check the proxy, CA certificate, client certificate and TLS version flags")
 	}
 
-	return p.execute(ctx)
+	p.Environment, err = EnvironmentFromContext(cmd)
+	if err != nil {
+		return err
+	}
+
+	p.Metadata.Pipeline.URL = p.Metadata.Links().Pipeline()
+
+	return nil
 }
 
 // Run the plugin.
+//
+// The context passed to the execute function is cancelled on SIGINT or SIGTERM.
+// If the execute function does not return within the shutdown timeout, the
+// process is terminated with the exit code of the received signal.
 func (p *Plugin) Run() {
-	if err := p.App.Run(context.Background(), os.Args); err != nil {
-		log.Error().Err(err).Msg("execution failed")
-		os.Exit(1)
+	ctx, stop := notifyContext(context.Background(), p.shutdownTimeout, os.Exit)
+
+	result := p.RunContext(ctx, os.Args)
+
+	stop()
+
+	if result.ExitCode != ExitCodeSuccess {
+		os.Exit(result.ExitCode)
+	}
+}
+
+// RunContext runs the plugin with the given context and arguments and returns
+// the result of the run instead of exiting the process. The outcome is logged
+// and, if configured, written to the result file.
+func (p *Plugin) RunContext(ctx context.Context, args []string) Result {
+	err := p.App.Run(ctx, args)
+
+	var sigErr *SignalError
+	if errors.As(context.Cause(ctx), &sigErr) {
+		err = NewError(CategoryInternal, errors.Join(sigErr, err)).WithCode(sigErr.ExitCode())
+	}
+
+	result := NewResult(err)
+	result.Reason = p.Secrets.Redact(result.Reason)
+
+	switch result.Status {
+	case StatusSkipped:
+		log.Info().Str("reason", result.Reason).Msg("execution skipped")
+	case StatusFailure:
+		logError(AsError(err))
+	case StatusSuccess:
+	}
+
+	if path := p.App.String("result-file"); path != "" {
+		if err := result.WriteFile(path); err != nil {
+			log.Error().Err(err).Str("path", path).Msg("failed to write result file")
+		}
+	}
+
+	return result
+}
+
+// logError prints a failure summary for the given error.
+func logError(err *Error) {
+	log.Error().
+		Err(err.Err).
+		Str("category", string(err.Category)).
+		Int("exit-code", err.Code).
+		Msg("execution failed")
+
+	if err.Hint != "" {
+		log.Info().Msgf("hint: %s", err.Hint)
 	}
 }
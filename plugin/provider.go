@@ -0,0 +1,264 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/urfave/cli/v3"
+)
+
+// MetadataProviderAuto selects the first metadata provider that detects its CI
+// system.
+const MetadataProviderAuto = "auto"
+
+var ErrUnknownMetadataProvider = errors.New("unknown metadata provider")
+
+// MetadataProvider maps the environment of a CI system to the metadata flags.
+// Values are only used for flags that are not set by the command line, the
+// `CI_*` environment variables or the config file.
+type MetadataProvider interface {
+	// Name of the provider as used by the metadata-provider flag.
+	Name() string
+	// Detect reports whether the plugin runs in the CI system of the provider.
+	Detect() bool
+	// Lookup returns the value of the metadata flag with the given name.
+	Lookup(flag string) (string, bool)
+}
+
+// EnvMetadataProvider is a MetadataProvider that reads the metadata flags from
+// environment variables.
+type EnvMetadataProvider struct {
+	// Name of the provider.
+	ProviderName string
+	// Environment variable that is set to DetectValue in the CI system. If empty,
+	// the provider is always detected.
+	DetectEnv   string
+	DetectValue string
+	// Environment variables mapped by the name of the metadata flag they are
+	// read into.
+	Vars map[string]string
+	// Functions computing the value of a metadata flag, used for values that
+	// have no direct equivalent in the environment.
+	Funcs map[string]func() (string, bool)
+}
+
+// Name implements the MetadataProvider interface.
+func (p *EnvMetadataProvider) Name() string {
+	return p.ProviderName
+}
+
+// Detect implements the MetadataProvider interface.
+func (p *EnvMetadataProvider) Detect() bool {
+	if p.DetectEnv == "" {
+		return true
+	}
+
+	return strings.EqualFold(os.Getenv(p.DetectEnv), p.DetectValue)
+}
+
+// Lookup implements the MetadataProvider interface.
+func (p *EnvMetadataProvider) Lookup(flag string) (string, bool) {
+	if fn, ok := p.Funcs[flag]; ok {
+		return fn()
+	}
+
+	if env, ok := p.Vars[flag]; ok {
+		return os.LookupEnv(env)
+	}
+
+	return "", false
+}
+
+// MetadataProviders returns the built-in metadata providers in the order they
+// are detected.
+func MetadataProviders() []MetadataProvider {
+	return []MetadataProvider{
+		WoodpeckerMetadataProvider(),
+		DroneMetadataProvider(),
+		GenericMetadataProvider(),
+	}
+}
+
+// WoodpeckerMetadataProvider returns the provider for Woodpecker CI. The
+// metadata flags read the `CI_*` variables of Woodpecker already, so no
+// additional variables are mapped.
+func WoodpeckerMetadataProvider() *EnvMetadataProvider {
+	return &EnvMetadataProvider{
+		ProviderName: "woodpecker",
+		DetectEnv:    "CI",
+		DetectValue:  "woodpecker",
+	}
+}
+
+// GenericMetadataProvider returns the provider for plain containers. Metadata
+// is only read from the command line, the `CI_*` variables and the config file.
+// Use the git-metadata flag to fill missing values from the git repository.
+func GenericMetadataProvider() *EnvMetadataProvider {
+	return &EnvMetadataProvider{
+		ProviderName: "generic",
+	}
+}
+
+// DroneMetadataProvider returns the provider for Drone CI, reading the
+// `DRONE_*` variables.
+func DroneMetadataProvider() *EnvMetadataProvider {
+	return &EnvMetadataProvider{
+		ProviderName: "drone",
+		DetectEnv:    "DRONE",
+		DetectValue:  "true",
+		Vars: map[string]string{
+			"repo.slug":              "DRONE_REPO",
+			"repo.name":              "DRONE_REPO_NAME",
+			"repo.owner":             "DRONE_REPO_OWNER",
+			"repo.url":               "DRONE_REPO_LINK",
+			"repo.clone-url":         "DRONE_GIT_HTTP_URL",
+			"repo.clone-ssh-url":     "DRONE_GIT_SSH_URL",
+			"repo.private":           "DRONE_REPO_PRIVATE",
+			"repo.default-branch":    "DRONE_REPO_BRANCH",
+			"pipeline.number":        "DRONE_BUILD_NUMBER",
+			"pipeline.status":        "DRONE_BUILD_STATUS",
+			"pipeline.url":           "DRONE_BUILD_LINK",
+			"pipeline.deploy-target": "DRONE_DEPLOY_TO",
+			"pipeline.created":       "DRONE_BUILD_CREATED",
+			"pipeline.started":       "DRONE_BUILD_STARTED",
+			"pipeline.finished":      "DRONE_BUILD_FINISHED",
+			"pipeline.parent":        "DRONE_BUILD_PARENT",
+			"workflow.name":          "DRONE_STAGE_NAME",
+			"workflow.number":        "DRONE_STAGE_NUMBER",
+			"workspace.path":         "DRONE_WORKSPACE",
+			"commit.url":             "DRONE_COMMIT_LINK",
+			"commit.sha":             "DRONE_COMMIT_SHA",
+			"commit.ref":             "DRONE_COMMIT_REF",
+			"commit.pull-request":    "DRONE_PULL_REQUEST",
+			"commit.source-branch":   "DRONE_SOURCE_BRANCH",
+			"commit.target-branch":   "DRONE_TARGET_BRANCH",
+			"commit.branch":          "DRONE_COMMIT_BRANCH",
+			"commit.tag":             "DRONE_TAG",
+			"commit.message":         "DRONE_COMMIT_MESSAGE",
+			"commit.author.name":     "DRONE_COMMIT_AUTHOR",
+			"commit.author.email":    "DRONE_COMMIT_AUTHOR_EMAIL",
+			"commit.author.avatar":   "DRONE_COMMIT_AUTHOR_AVATAR",
+			"prev.commit.sha":        "DRONE_COMMIT_BEFORE",
+			"step.name":              "DRONE_STEP_NAME",
+			"step.number":            "DRONE_STEP_NUMBER",
+			"system.host":            "DRONE_SYSTEM_HOST",
+			"system.version":         "DRONE_SYSTEM_VERSION",
+		},
+		Funcs: map[string]func() (string, bool){
+			"pipeline.event": droneEvent,
+			"system.name":    func() (string, bool) { return "drone", true },
+			"system.url":     droneSystemURL,
+			"system.arch":    droneArch,
+		},
+	}
+}
+
+// droneEvent maps the Drone build events to the Woodpecker pipeline events.
+func droneEvent() (string, bool) {
+	event, ok := os.LookupEnv("DRONE_BUILD_EVENT")
+	if !ok {
+		return "", false
+	}
+
+	switch event {
+	case "promote", "rollback":
+		return string(EventDeployment), true
+	case "custom":
+		return string(EventManual), true
+	}
+
+	return event, true
+}
+
+func droneSystemURL() (string, bool) {
+	host := os.Getenv("DRONE_SYSTEM_HOST")
+	if host == "" {
+		return "", false
+	}
+
+	proto := os.Getenv("DRONE_SYSTEM_PROTO")
+	if proto == "" {
+		proto = "https"
+	}
+
+	return proto + "://" + host, true
+}
+
+func droneArch() (string, bool) {
+	goos, goarch := os.Getenv("DRONE_STAGE_OS"), os.Getenv("DRONE_STAGE_ARCH")
+	if goos == "" || goarch == "" {
+		return "", false
+	}
+
+	return goos + "/" + goarch, true
+}
+
+func metadataProviderFlags(category string) []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:     "metadata-provider",
+			Usage:    "CI system to read the metadata from, auto-detected by default",
+			Sources:  cli.EnvVars("PLUGIN_METADATA_PROVIDER"),
+			Value:    MetadataProviderAuto,
+			Category: category,
+		},
+	}
+}
+
+// selectMetadataProvider returns the provider with the given name or, if the
+// name is auto, the first provider that detects its CI system.
+//
+//nolint:ireturn
+func selectMetadataProvider(name string, providers []MetadataProvider) (MetadataProvider, error) {
+	for _, provider := range providers {
+		if name == MetadataProviderAuto && provider.Detect() || strings.EqualFold(name, provider.Name()) {
+			return provider, nil
+		}
+	}
+
+	if name == MetadataProviderAuto {
+		return GenericMetadataProvider(), nil
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrUnknownMetadataProvider, name)
+}
+
+// metadataProviderNames returns the names accepted by the metadata-provider
+// flag.
+func metadataProviderNames(providers []MetadataProvider) string {
+	names := []string{MetadataProviderAuto}
+
+	for _, provider := range providers {
+		if !slices.Contains(names, provider.Name()) {
+			names = append(names, provider.Name())
+		}
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// applyMetadataProvider sets all metadata flags that are not set yet to the
+// values of the provider. Invalid values are logged and ignored.
+func applyMetadataProvider(cmd *cli.Command, provider MetadataProvider) {
+	for _, flag := range metadataFlags() {
+		name := flag.Names()[0]
+		if cmd.IsSet(name) {
+			continue
+		}
+
+		value, ok := provider.Lookup(name)
+		if !ok || value == "" {
+			continue
+		}
+
+		if err := cmd.Set(name, value); err != nil {
+			log.Warn().Err(err).Str("provider", provider.Name()).Str("flag", name).Msg("ignoring invalid metadata value")
+		}
+	}
+
+	log.Debug().Str("provider", provider.Name()).Msg("metadata provider selected")
+}
@@ -0,0 +1,164 @@
+package plugin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v3"
+)
+
+func TestMetadataProvider(t *testing.T) {
+	drone := map[string]string{
+		"DRONE":               "true",
+		"DRONE_REPO":          "octocat/hello-world",
+		"DRONE_REPO_NAME":     "hello-world",
+		"DRONE_REPO_OWNER":    "octocat",
+		"DRONE_GIT_HTTP_URL":  "https://example.com/octocat/hello-world.git",
+		"DRONE_REPO_PRIVATE":  "true",
+		"DRONE_BUILD_NUMBER":  "42",
+		"DRONE_BUILD_EVENT":   "promote",
+		"DRONE_BUILD_CREATED": "1700000000",
+		"DRONE_DEPLOY_TO":     "production",
+		"DRONE_COMMIT_SHA":    "abc",
+		"DRONE_COMMIT_BRANCH": "main",
+		"DRONE_STAGE_OS":      "linux",
+		"DRONE_STAGE_ARCH":    "amd64",
+		"DRONE_SYSTEM_HOST":   "drone.example.com",
+		"DRONE_SYSTEM_PROTO":  "http",
+	}
+
+	tests := []struct {
+		name    string
+		envs    map[string]string
+		args    []string
+		want    func(t *testing.T, m Metadata)
+		wantErr error
+	}{
+		{
+			name: "detect drone",
+			envs: drone,
+			want: func(t *testing.T, m Metadata) {
+				t.Helper()
+
+				assert.Equal(t, "octocat/hello-world", m.Repository.Slug)
+				assert.Equal(t, "hello-world", m.Repository.Name)
+				assert.Equal(t, "octocat", m.Repository.Owner)
+				assert.Equal(t, "https://example.com/octocat/hello-world.git", m.Repository.CloneURL)
+				assert.True(t, m.Repository.Private)
+				assert.Equal(t, int64(42), m.Pipeline.Number)
+				assert.Equal(t, EventDeployment, m.Pipeline.Event)
+				assert.Equal(t, int64(1700000000), m.Pipeline.Created.Unix())
+				assert.Equal(t, "production", m.Pipeline.DeployTarget)
+				assert.Equal(t, "abc", m.Curr.SHA)
+				assert.Equal(t, "main", m.Curr.Branch)
+				assert.Equal(t, "drone", m.System.Name)
+				assert.Equal(t, "http://drone.example.com", m.System.URL)
+				assert.Equal(t, "linux/amd64", m.System.Platform)
+			},
+		},
+		{
+			name: "ci variables take precedence",
+			envs: merge(drone, map[string]string{"CI_COMMIT_SHA": "def"}),
+			want: func(t *testing.T, m Metadata) {
+				t.Helper()
+
+				assert.Equal(t, "def", m.Curr.SHA)
+				assert.Equal(t, "main", m.Curr.Branch)
+			},
+		},
+		{
+			name: "command line takes precedence",
+			envs: drone,
+			args: []string{"--commit.branch", "release"},
+			want: func(t *testing.T, m Metadata) {
+				t.Helper()
+
+				assert.Equal(t, "release", m.Curr.Branch)
+			},
+		},
+		{
+			name: "select generic",
+			envs: merge(drone, map[string]string{"PLUGIN_METADATA_PROVIDER": "generic"}),
+			want: func(t *testing.T, m Metadata) {
+				t.Helper()
+
+				assert.Empty(t, m.Repository.Slug)
+				assert.Empty(t, m.System.Name)
+			},
+		},
+		{
+			name: "detect woodpecker",
+			envs: merge(drone, map[string]string{"CI": "woodpecker", "CI_REPO": "octocat/other"}),
+			want: func(t *testing.T, m Metadata) {
+				t.Helper()
+
+				assert.Equal(t, "octocat/other", m.Repository.Slug)
+				assert.Empty(t, m.Curr.SHA)
+			},
+		},
+		{
+			name:    "unknown provider",
+			envs:    map[string]string{"PLUGIN_METADATA_PROVIDER": "jenkins"},
+			wantErr: ErrUnknownMetadataProvider,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.envs {
+				t.Setenv(key, value)
+			}
+
+			p := New(Options{Name: "dummy"})
+			p.App.Action = func(_ context.Context, cmd *cli.Command) error {
+				return p.setup(cmd)
+			}
+
+			err := p.App.Run(t.Context(), append([]string{"dummy"}, tt.args...))
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			tt.want(t, p.Metadata)
+		})
+	}
+}
+
+func TestMetadataProviderCustom(t *testing.T) {
+	t.Setenv("BUILD_COMMIT", "abc")
+	t.Setenv("CUSTOM_CI", "yes")
+
+	p := New(Options{
+		Name: "dummy",
+		MetadataProviders: []MetadataProvider{
+			&EnvMetadataProvider{
+				ProviderName: "custom",
+				DetectEnv:    "CUSTOM_CI",
+				DetectValue:  "yes",
+				Vars:         map[string]string{"commit.sha": "BUILD_COMMIT"},
+			},
+		},
+	})
+	p.App.Action = func(_ context.Context, cmd *cli.Command) error {
+		return p.setup(cmd)
+	}
+
+	assert.NoError(t, p.App.Run(t.Context(), []string{"dummy"}))
+	assert.Equal(t, "abc", p.Metadata.Curr.SHA)
+}
+
+func merge(maps ...map[string]string) map[string]string {
+	result := make(map[string]string)
+
+	for _, m := range maps {
+		for key, value := range m {
+			result[key] = value
+		}
+	}
+
+	return result
+}
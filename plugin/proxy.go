@@ -0,0 +1,366 @@
+package plugin
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	plugin_cli "github.com/thegeeklab/wp-plugin-go/v6/cli"
+	"github.com/urfave/cli/v3"
+)
+
+// ProxyDirect is the per-host proxy override to connect without proxy.
+const ProxyDirect = "direct"
+
+var ErrInvalidProxy = errors.New("invalid proxy")
+
+// proxyConfig selects the proxy of a request. Per-host overrides take
+// precedence over the no-proxy list, followed by the SOCKS proxy and the HTTP
+// or HTTPS proxy matching the scheme of the request. Like the proxy settings
+// of the environment in net/http, requests to localhost and loopback
+// addresses do not use a proxy unless set by an override.
+type proxyConfig struct {
+	http      *url.URL
+	https     *url.URL
+	socks     *url.URL
+	noProxy   []hostMatcher
+	overrides []proxyOverride
+}
+
+type proxyOverride struct {
+	match hostMatcher
+	proxy *url.URL
+}
+
+// hostMatcher matches hosts against an entry of a no-proxy list. An entry is
+// either `*`, an IP address, a CIDR range or a domain name, optionally with a
+// port. A domain matches itself and its subdomains, with a leading dot or
+// `*.` it only matches subdomains.
+type hostMatcher struct {
+	pattern    string
+	all        bool
+	ip         net.IP
+	network    *net.IPNet
+	domain     string
+	subdomains bool
+	port       string
+}
+
+func proxyFlags(category string) []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:     "transport.http-proxy",
+			Usage:    "proxy URL for HTTP requests",
+			Sources:  cli.EnvVars("PLUGIN_HTTP_PROXY", "HTTP_PROXY", "http_proxy"),
+			Category: category,
+		},
+		&cli.StringFlag{
+			Name:     "transport.https-proxy",
+			Usage:    "proxy URL for HTTPS requests",
+			Sources:  cli.EnvVars("PLUGIN_HTTPS_PROXY", "HTTPS_PROXY", "https_proxy"),
+			Category: category,
+		},
+		&cli.StringFlag{
+			Name:     "transport.socks-proxy",
+			Usage:    "SOCKS5 proxy address for all requests, takes precedence over the HTTP and HTTPS proxy",
+			Sources:  cli.EnvVars("PLUGIN_SOCKS_PROXY", "SOCKS_PROXY"),
+			Category: category,
+		},
+		&cli.BoolFlag{
+			Name:    "transport.socks-proxy-off",
+			Usage:   "socks proxy ignored",
+			Sources: cli.EnvVars("SOCKS_PROXY_OFF"),
+			Hidden:  true,
+		},
+		&cli.StringFlag{
+			Name:     "transport.no-proxy",
+			Usage:    "comma separated hosts, domains, IP addresses or CIDR ranges to connect to without proxy",
+			Sources:  cli.EnvVars("PLUGIN_NO_PROXY", "NO_PROXY", "no_proxy"),
+			Category: category,
+		},
+		&cli.StringFlag{
+			Name:     "transport.proxy-username",
+			Usage:    "username for proxies without credentials in the URL",
+			Sources:  cli.EnvVars("PLUGIN_PROXY_USERNAME"),
+			Category: category,
+		},
+		&cli.StringFlag{
+			Name:     "transport.proxy-password",
+			Usage:    "password for proxies without credentials in the URL",
+			Sources:  cli.EnvVars("PLUGIN_PROXY_PASSWORD"),
+			Category: category,
+		},
+		&plugin_cli.StringMapFlag{
+			Name: "transport.proxy-hosts",
+			Usage: "JSON map of host patterns to proxy URLs or \"direct\", overrides the proxy selection, " +
+				"the longest matching pattern wins",
+			Sources:  cli.EnvVars("PLUGIN_PROXY_HOSTS"),
+			Category: category,
+		},
+	}
+}
+
+// proxyConfigFromContext creates the proxy configuration of the network flags.
+func proxyConfigFromContext(cmd *cli.Command) (*proxyConfig, error) {
+	var (
+		config = &proxyConfig{}
+		user   *url.Userinfo
+		err    error
+	)
+
+	if username := cmd.String("transport.proxy-username"); username != "" {
+		user = url.UserPassword(username, cmd.String("transport.proxy-password"))
+	}
+
+	if config.http, err = parseProxyURL("http proxy", cmd.String("transport.http-proxy"), "http", user); err != nil {
+		return nil, err
+	}
+
+	if config.https, err = parseProxyURL("https proxy", cmd.String("transport.https-proxy"), "http", user); err != nil {
+		return nil, err
+	}
+
+	if !cmd.Bool("transport.socks-proxy-off") {
+		config.socks, err = parseProxyURL("socks proxy", cmd.String("transport.socks-proxy"), "socks5", user)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for entry := range strings.SplitSeq(cmd.String("transport.no-proxy"), ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+
+		config.noProxy = append(config.noProxy, parseHostMatcher(entry))
+	}
+
+	hosts, _ := cmd.Value("transport.proxy-hosts").(map[string]string)
+
+	for pattern, value := range hosts {
+		override := proxyOverride{match: parseHostMatcher(pattern)}
+
+		if !strings.EqualFold(strings.TrimSpace(value), ProxyDirect) {
+			name := fmt.Sprintf("proxy of host %q", pattern)
+
+			if override.proxy, err = parseProxyURL(name, value, "http", user); err != nil {
+				return nil, err
+			}
+
+			if override.proxy == nil {
+				return nil, fmt.Errorf("%w: %s is empty, use %q to disable it", ErrInvalidProxy, name, ProxyDirect)
+			}
+		}
+
+		config.overrides = append(config.overrides, override)
+	}
+
+	slices.SortFunc(config.overrides, func(a, b proxyOverride) int {
+		return cmp.Or(
+			cmp.Compare(len(b.match.pattern), len(a.match.pattern)),
+			cmp.Compare(a.match.pattern, b.match.pattern),
+		)
+	})
+
+	return config, nil
+}
+
+// proxyPasswords returns the passwords embedded in the proxy URLs of the
+// network flags, both as given and URL-decoded. The URLs are not validated to
+// also cover invalid URLs that end up in error messages.
+func proxyPasswords(cmd *cli.Command) []string {
+	values := []string{
+		cmd.String("transport.http-proxy"),
+		cmd.String("transport.https-proxy"),
+		cmd.String("transport.socks-proxy"),
+	}
+
+	hosts, _ := cmd.Value("transport.proxy-hosts").(map[string]string)
+	for _, value := range hosts {
+		values = append(values, value)
+	}
+
+	passwords := make([]string, 0)
+
+	for _, value := range values {
+		if _, after, ok := strings.Cut(value, "://"); ok {
+			value = after
+		}
+
+		if i := strings.IndexAny(value, "/?#"); i >= 0 {
+			value = value[:i]
+		}
+
+		i := strings.LastIndex(value, "@")
+		if i < 0 {
+			continue
+		}
+
+		_, password, ok := strings.Cut(value[:i], ":")
+		if !ok || password == "" {
+			continue
+		}
+
+		passwords = append(passwords, password)
+
+		if decoded, err := url.PathUnescape(password); err == nil && decoded != password {
+			passwords = append(passwords, decoded)
+		}
+	}
+
+	return passwords
+}
+
+// Proxy returns the proxy URL of the request, nil if the request does not use
+// a proxy. It implements the Proxy function of http.Transport.
+//
+//nolint:nilnil
+func (c *proxyConfig) Proxy(req *http.Request) (*url.URL, error) {
+	host := strings.ToLower(req.URL.Hostname())
+	port := req.URL.Port()
+
+	if port == "" {
+		port = map[string]string{"http": "80", "https": "443"}[req.URL.Scheme]
+	}
+
+	for _, override := range c.overrides {
+		if override.match.Match(host, port) {
+			return override.proxy, nil
+		}
+	}
+
+	if host == "localhost" || isLoopback(host) {
+		return nil, nil
+	}
+
+	for _, matcher := range c.noProxy {
+		if matcher.Match(host, port) {
+			return nil, nil
+		}
+	}
+
+	if c.socks != nil {
+		return c.socks, nil
+	}
+
+	if req.URL.Scheme == "https" {
+		return c.https, nil
+	}
+
+	return c.http, nil
+}
+
+// parseProxyURL parses a proxy URL. The scheme defaults to the given scheme.
+// If the URL has no credentials, the given user is set. An empty value
+// returns nil.
+//
+//nolint:nilnil
+func parseProxyURL(name, value, scheme string, user *url.Userinfo) (*url.URL, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+
+	if !strings.Contains(value, "://") {
+		value = scheme + "://" + value
+	}
+
+	proxyURL, err := url.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidProxy, name, err)
+	}
+
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("%w: %s: unsupported scheme %q", ErrInvalidProxy, name, proxyURL.Scheme)
+	}
+
+	if proxyURL.Hostname() == "" {
+		return nil, fmt.Errorf("%w: %s: missing host in %q", ErrInvalidProxy, name, proxyURL.Redacted())
+	}
+
+	if proxyURL.User == nil {
+		proxyURL.User = user
+	}
+
+	return proxyURL, nil
+}
+
+func parseHostMatcher(entry string) hostMatcher {
+	entry = strings.ToLower(strings.TrimSpace(entry))
+	matcher := hostMatcher{pattern: entry}
+
+	if entry == "*" {
+		matcher.all = true
+
+		return matcher
+	}
+
+	if _, network, err := net.ParseCIDR(entry); err == nil {
+		matcher.network = network
+
+		return matcher
+	}
+
+	host := entry
+	if h, p, err := net.SplitHostPort(entry); err == nil {
+		host, matcher.port = h, p
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		matcher.ip = ip
+
+		return matcher
+	}
+
+	if trimmed, ok := strings.CutPrefix(host, "*"); ok {
+		host = trimmed
+	}
+
+	if trimmed, ok := strings.CutPrefix(host, "."); ok {
+		host = trimmed
+		matcher.subdomains = true
+	}
+
+	matcher.domain = host
+
+	return matcher
+}
+
+// Match reports whether the lower case host and port match.
+func (m hostMatcher) Match(host, port string) bool {
+	if m.port != "" && m.port != port {
+		return false
+	}
+
+	switch {
+	case m.all:
+		return true
+	case m.network != nil:
+		ip := net.ParseIP(host)
+
+		return ip != nil && m.network.Contains(ip)
+	case m.ip != nil:
+		return m.ip.Equal(net.ParseIP(host))
+	case m.domain == "":
+		return false
+	}
+
+	if host == m.domain {
+		return !m.subdomains
+	}
+
+	return strings.HasSuffix(host, "."+m.domain)
+}
+
+func isLoopback(host string) bool {
+	ip := net.ParseIP(host)
+
+	return ip != nil && ip.IsLoopback()
+}
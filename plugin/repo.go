@@ -20,14 +20,16 @@ import (
 
 // Repository defines runtime metadata for a repository.
 type Repository struct {
-	Slug     string
-	Name     string
-	Owner    string
-	URL      string
-	CloneURL string
-	Private  bool
-	Branch   string
-	RemoteID int64
+	Slug        string `json:"slug"`
+	Name        string `json:"name"`
+	Owner       string `json:"owner"`
+	URL         string `json:"url"`
+	CloneURL    string `json:"clone_url"`
+	CloneSSHURL string `json:"clone_ssh_url"`
+	Private     bool   `json:"private"`
+	Trusted     bool   `json:"trusted"`
+	Branch      string `json:"branch"`
+	RemoteID    int64  `json:"remote_id"`
 }
 
 func repositoryFlags(category string) []cli.Flag {
@@ -62,12 +64,24 @@ func repositoryFlags(category string) []cli.Flag {
 			Sources:  cli.EnvVars("CI_REPO_CLONE_URL"),
 			Category: category,
 		},
+		&cli.StringFlag{
+			Name:     "repo.clone-ssh-url",
+			Usage:    "repo clone ssh url",
+			Sources:  cli.EnvVars("CI_REPO_CLONE_SSH_URL"),
+			Category: category,
+		},
 		&cli.BoolFlag{
 			Name:     "repo.private",
 			Usage:    "repo private",
 			Sources:  cli.EnvVars("CI_REPO_PRIVATE"),
 			Category: category,
 		},
+		&cli.BoolFlag{
+			Name:     "repo.trusted",
+			Usage:    "repo trusted",
+			Sources:  cli.EnvVars("CI_REPO_TRUSTED"),
+			Category: category,
+		},
 		&cli.StringFlag{
 			Name:     "repo.default-branch",
 			Usage:    "repo default branch",
@@ -85,13 +99,15 @@ func repositoryFlags(category string) []cli.Flag {
 
 func repositoryFromContext(c *cli.Command) Repository {
 	return Repository{
-		Slug:     c.String("repo.slug"),
-		Name:     c.String("repo.name"),
-		Owner:    c.String("repo.owner"),
-		URL:      c.String("repo.url"),
-		CloneURL: c.String("repo.clone-url"),
-		Private:  c.Bool("repo.private"),
-		Branch:   c.String("repo.default-branch"),
-		RemoteID: c.Int64("repo.remote-id"),
+		Slug:        c.String("repo.slug"),
+		Name:        c.String("repo.name"),
+		Owner:       c.String("repo.owner"),
+		URL:         c.String("repo.url"),
+		CloneURL:    c.String("repo.clone-url"),
+		CloneSSHURL: c.String("repo.clone-ssh-url"),
+		Private:     c.Bool("repo.private"),
+		Trusted:     c.Bool("repo.trusted"),
+		Branch:      c.String("repo.default-branch"),
+		RemoteID:    c.Int64("repo.remote-id"),
 	}
 }
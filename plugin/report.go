@@ -0,0 +1,263 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/thegeeklab/wp-plugin-go/v6/file"
+	"github.com/thegeeklab/wp-plugin-go/v6/template"
+	"github.com/urfave/cli/v3"
+)
+
+// ReportBlockKind defines the kind of a report block.
+type ReportBlockKind string
+
+const (
+	ReportHeading   ReportBlockKind = "heading"
+	ReportParagraph ReportBlockKind = "paragraph"
+	ReportTable     ReportBlockKind = "table"
+	ReportFields    ReportBlockKind = "fields"
+	ReportLinks     ReportBlockKind = "links"
+)
+
+// ReportField is a key/value pair of a report field list.
+type ReportField struct {
+	Key   string
+	Value string
+}
+
+// ReportLink is a link of a report link list.
+type ReportLink struct {
+	Text string
+	URL  string
+}
+
+// ReportBlock is a single element of a report. Only the fields of the block
+// kind are set.
+type ReportBlock struct {
+	Kind   ReportBlockKind
+	Level  int
+	Text   string
+	Header []string
+	Rows   [][]string
+	Fields []ReportField
+	Links  []ReportLink
+}
+
+// ReportData is the payload passed to custom report templates.
+type ReportData struct {
+	// Blocks of the report in the order they were added.
+	Blocks []ReportBlock
+	// Default Markdown rendering of the report.
+	Markdown string
+	// Metadata of the current pipeline.
+	Metadata Metadata
+}
+
+// Report builds a human-readable summary of the plugin run. It is safe for
+// concurrent use.
+type Report struct {
+	mu      sync.Mutex
+	blocks  []ReportBlock
+	secrets *Secrets
+}
+
+// NewReport creates an empty report.
+func NewReport() *Report {
+	return &Report{}
+}
+
+func reportFlags(category string) []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:     "report-file",
+			Usage:    "path to write the step report to",
+			Sources:  cli.EnvVars("PLUGIN_REPORT_FILE"),
+			Category: category,
+		},
+		&cli.StringFlag{
+			Name:     "report-template",
+			Usage:    "custom template for the step report, can be a file path, URL or inline template",
+			Sources:  cli.EnvVars("PLUGIN_REPORT_TEMPLATE"),
+			Category: category,
+		},
+	}
+}
+
+func (r *Report) add(block ReportBlock) *Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.blocks = append(r.blocks, block)
+
+	return r
+}
+
+// Heading adds a heading of the given level, limited to 1 to 6.
+func (r *Report) Heading(level int, text string) *Report {
+	//nolint:mnd
+	return r.add(ReportBlock{Kind: ReportHeading, Level: min(max(level, 1), 6), Text: text})
+}
+
+// Paragraph adds a paragraph with the formatted text.
+func (r *Report) Paragraph(format string, args ...any) *Report {
+	return r.add(ReportBlock{Kind: ReportParagraph, Text: fmt.Sprintf(format, args...)})
+}
+
+// Table adds a table with the given header and rows.
+func (r *Report) Table(header []string, rows ...[]string) *Report {
+	return r.add(ReportBlock{Kind: ReportTable, Header: slices.Clone(header), Rows: slices.Clone(rows)})
+}
+
+// Fields adds a list of key/value pairs.
+func (r *Report) Fields(fields ...ReportField) *Report {
+	return r.add(ReportBlock{Kind: ReportFields, Fields: slices.Clone(fields)})
+}
+
+// Links adds a list of links.
+func (r *Report) Links(links ...ReportLink) *Report {
+	return r.add(ReportBlock{Kind: ReportLinks, Links: slices.Clone(links)})
+}
+
+// Blocks returns a copy of the report blocks.
+func (r *Report) Blocks() []ReportBlock {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return slices.Clone(r.blocks)
+}
+
+// Empty reports whether no blocks were added to the report.
+func (r *Report) Empty() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.blocks) == 0
+}
+
+// String returns the report formatted as Markdown. Secrets of the plugin are
+// redacted.
+func (r *Report) String() string {
+	blocks := r.Blocks()
+	parts := make([]string, 0, len(blocks))
+
+	for _, block := range blocks {
+		parts = append(parts, block.markdown())
+	}
+
+	if len(parts) == 0 {
+		return ""
+	}
+
+	return r.secrets.Redact(strings.Join(parts, "\n\n") + "\n")
+}
+
+// Render returns the report formatted with the given template. The template
+// can be a file path, URL or inline template and receives the ReportData as
+// payload. Without template, the Markdown formatting is returned.
+func (r *Report) Render(ctx context.Context, client *http.Client, tmpl string, metadata Metadata) (string, error) {
+	markdown := r.String()
+
+	if tmpl == "" {
+		return markdown, nil
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	content, err := template.Render(ctx, *client, tmpl, ReportData{
+		Blocks:   r.Blocks(),
+		Markdown: markdown,
+		Metadata: metadata,
+	})
+
+	return r.secrets.Redact(content), err
+}
+
+// WriteFile atomically writes the rendered report to the given path.
+func (r *Report) WriteFile(
+	ctx context.Context, client *http.Client, path, tmpl string, metadata Metadata,
+) (string, error) {
+	content, err := r.Render(ctx, client, tmpl, metadata)
+	if err != nil {
+		return "", fmt.Errorf("failed to render report: %w", err)
+	}
+
+	//nolint:mnd
+	return content, file.WriteFileAtomic(path, []byte(content), 0o644)
+}
+
+func (b ReportBlock) markdown() string {
+	var sb strings.Builder
+
+	switch b.Kind {
+	case ReportHeading:
+		sb.WriteString(strings.Repeat("#", b.Level) + " " + b.Text)
+	case ReportParagraph:
+		sb.WriteString(b.Text)
+	case ReportTable:
+		header := make([]string, len(b.Header))
+		separator := make([]string, len(b.Header))
+
+		for i, cell := range b.Header {
+			header[i] = escapeTableCell(cell)
+			separator[i] = "---"
+		}
+
+		sb.WriteString("| " + strings.Join(header, " | ") + " |\n")
+		sb.WriteString("| " + strings.Join(separator, " | ") + " |")
+
+		for _, row := range b.Rows {
+			cells := make([]string, len(b.Header))
+
+			for i := range cells {
+				if i < len(row) {
+					cells[i] = escapeTableCell(row[i])
+				}
+			}
+
+			sb.WriteString("\n| " + strings.Join(cells, " | ") + " |")
+		}
+	case ReportFields:
+		for i, field := range b.Fields {
+			if i > 0 {
+				sb.WriteString("\n")
+			}
+
+			fmt.Fprintf(&sb, "- **%s:** %s", field.Key, field.Value)
+		}
+	case ReportLinks:
+		for i, link := range b.Links {
+			if i > 0 {
+				sb.WriteString("\n")
+			}
+
+			sb.WriteString("- " + MarkdownLink(link.Text, link.URL))
+		}
+	}
+
+	return sb.String()
+}
+
+// MarkdownLink returns a Markdown link, e.g. to be used in table cells. If the
+// URL is empty, the plain text is returned.
+func MarkdownLink(text, url string) string {
+	if url == "" {
+		return text
+	}
+
+	if text == "" {
+		text = url
+	}
+
+	return fmt.Sprintf("[%s](%s)", text, url)
+}
+
+func escapeTableCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>").Replace(s)
+}
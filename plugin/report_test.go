@@ -0,0 +1,143 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportString(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func(r *Report)
+		want  string
+	}{
+		{
+			name:  "empty",
+			build: func(_ *Report) {},
+			want:  "",
+		},
+		{
+			name: "heading and paragraph",
+			build: func(r *Report) {
+				r.Heading(2, "Release").Paragraph("Published %d tags.", 2)
+			},
+			want: "## Release\n\nPublished 2 tags.\n",
+		},
+		{
+			name: "heading level is limited",
+			build: func(r *Report) {
+				r.Heading(9, "Deep").Heading(0, "Top")
+			},
+			want: "###### Deep\n\n# Top\n",
+		},
+		{
+			name: "table",
+			build: func(r *Report) {
+				r.Table(
+					[]string{"Tag", "Digest"},
+					[]string{"latest", "sha256:abc"},
+					[]string{"a|b", "multi\nline"},
+					[]string{"short"},
+				)
+			},
+			want: "| Tag | Digest |\n| --- | --- |\n| latest | sha256:abc |\n| a\\|b | multi<br>line |\n| short |  |\n",
+		},
+		{
+			name: "fields and links",
+			build: func(r *Report) {
+				r.Fields(ReportField{Key: "Duration", Value: "3s"}, ReportField{Key: "Size", Value: "12MB"})
+				r.Links(ReportLink{Text: "Artifact", URL: "https://example.com/a.tar"}, ReportLink{URL: "https://example.com"})
+			},
+			want: "- **Duration:** 3s\n- **Size:** 12MB\n\n- [Artifact](https://example.com/a.tar)\n" +
+				"- [https://example.com](https://example.com)\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewReport()
+			tt.build(r)
+
+			assert.Equal(t, tt.want, r.String())
+			assert.Equal(t, tt.want == "", r.Empty())
+		})
+	}
+}
+
+func TestReportRender(t *testing.T) {
+	r := NewReport().Heading(1, "Summary").Fields(ReportField{Key: "Tag", Value: "v1.0.0"})
+	metadata := Metadata{Repository: Repository{Slug: "octocat/hello-world"}}
+
+	tests := []struct {
+		name string
+		tmpl string
+		want string
+	}{
+		{
+			name: "default markdown",
+			want: "# Summary\n\n- **Tag:** v1.0.0\n",
+		},
+		{
+			name: "custom template",
+			tmpl: "{{ .Metadata.Repository.Slug }}:{{ range .Blocks }} {{ .Kind }}{{ end }}",
+			want: "octocat/hello-world: heading fields",
+		},
+		{
+			name: "custom template with markdown",
+			tmpl: "Report\n\n{{ .Markdown }}",
+			want: "Report\n\n# Summary\n\n- **Tag:** v1.0.0\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.Render(t.Context(), nil, tt.tmpl, metadata)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPluginReportFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		execErr error
+	}{
+		{
+			name: "success",
+		},
+		{
+			name:    "failure",
+			execErr: errDummy,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "report.md")
+			t.Setenv("PLUGIN_REPORT_FILE", path)
+
+			var p *Plugin
+
+			p = New(Options{
+				Name: "dummy",
+				Execute: func(_ context.Context) error {
+					p.Report.Heading(1, "Summary")
+
+					return tt.execErr
+				},
+			})
+
+			_ = p.App.Run(t.Context(), []string{"dummy"})
+
+			data, err := os.ReadFile(path)
+			assert.NoError(t, err)
+			assert.Equal(t, "# Summary\n", string(data))
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/thegeeklab/wp-plugin-go/v6/file"
+	"github.com/urfave/cli/v3"
+)
+
+// ErrSkip is the sentinel error all skip errors match with errors.Is.
+var ErrSkip = errors.New("skipped")
+
+// SkipError signals that the plugin decided there is nothing to do. It is not
+// treated as failure.
+type SkipError struct {
+	Reason string
+}
+
+func (e *SkipError) Error() string {
+	if e.Reason == "" {
+		return ErrSkip.Error()
+	}
+
+	return fmt.Sprintf("%s: %s", ErrSkip, e.Reason)
+}
+
+func (e *SkipError) Is(target error) bool {
+	return target == ErrSkip
+}
+
+// Skip returns a SkipError with the formatted reason. Return it from any
+// lifecycle function to end the run without failing the step.
+func Skip(format string, args ...any) error {
+	return &SkipError{Reason: fmt.Sprintf(format, args...)}
+}
+
+// asSkip returns the SkipError if it is the only error of the chain. Errors
+// joined to a skip error, e.g. a failed cleanup, make the run fail.
+func asSkip(err error) (*SkipError, bool) {
+	for err != nil {
+		if skipErr, ok := err.(*SkipError); ok { //nolint:errorlint
+			return skipErr, true
+		}
+
+		err = errors.Unwrap(err)
+	}
+
+	return nil, false
+}
+
+// Status defines the outcome of a plugin run.
+type Status string
+
+const (
+	StatusSuccess Status = "success"
+	StatusSkipped Status = "skipped"
+	StatusFailure Status = "failure"
+)
+
+// Result is the machine-readable outcome of a plugin run.
+type Result struct {
+	Status   Status   `json:"status"`
+	Reason   string   `json:"reason,omitempty"`
+	Category Category `json:"category,omitempty"`
+	ExitCode int      `json:"exit_code"`
+}
+
+func resultFlags(category string) []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:     "result-file",
+			Usage:    "path to write the machine-readable step result to",
+			Sources:  cli.EnvVars("PLUGIN_RESULT_FILE"),
+			Category: category,
+		},
+	}
+}
+
+// NewResult creates the Result for the error returned by a plugin run.
+func NewResult(err error) Result {
+	if err == nil {
+		return Result{
+			Status:   StatusSuccess,
+			ExitCode: ExitCodeSuccess,
+		}
+	}
+
+	if skipErr, ok := asSkip(err); ok {
+		return Result{
+			Status:   StatusSkipped,
+			Reason:   skipErr.Reason,
+			ExitCode: ExitCodeSuccess,
+		}
+	}
+
+	perr := AsError(err)
+
+	return Result{
+		Status:   StatusFailure,
+		Reason:   perr.Error(),
+		Category: perr.Category,
+		ExitCode: perr.ExitCode(),
+	}
+}
+
+// WriteFile atomically writes the result as JSON to the given path.
+func (r Result) WriteFile(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	//nolint:mnd
+	return file.WriteFileAtomic(path, append(data, '\n'), 0o644)
+}
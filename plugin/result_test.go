@@ -0,0 +1,72 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewResult(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Result
+	}{
+		{
+			name: "success",
+			want: Result{Status: StatusSuccess, ExitCode: ExitCodeSuccess},
+		},
+		{
+			name: "skipped",
+			err:  fmt.Errorf("before execute: %w", Skip("ref %s is not taggable", "refs/heads/dev")),
+			want: Result{Status: StatusSkipped, Reason: "ref refs/heads/dev is not taggable", ExitCode: ExitCodeSuccess},
+		},
+		{
+			name: "skip joined with error",
+			err:  NewError(CategoryInternal, errors.Join(errDummy, Skip("nothing to do"))),
+			want: Result{
+				Status:   StatusFailure,
+				Reason:   errDummy.Error() + "\nskipped: nothing to do",
+				Category: CategoryInternal,
+				ExitCode: ExitCodeInternal,
+			},
+		},
+		{
+			name: "failure",
+			err:  NewError(CategoryConfig, errDummy),
+			want: Result{
+				Status:   StatusFailure,
+				Reason:   errDummy.Error(),
+				Category: CategoryConfig,
+				ExitCode: ExitCodeConfig,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NewResult(tt.err))
+		})
+	}
+}
+
+func TestResultWriteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "result.json")
+	want := NewResult(Skip("nothing to do"))
+
+	assert.NoError(t, want.WriteFile(path))
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+
+	var got Result
+
+	assert.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, want, got)
+	assert.Contains(t, string(data), `"status": "skipped"`)
+}
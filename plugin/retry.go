@@ -0,0 +1,203 @@
+package plugin
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	RetryMaxAttempts = 3
+	RetryMaxTime     = time.Minute
+	RetryDelay       = time.Second
+	RetryMaxDelay    = 30 * time.Second
+
+	// retryDrainLimit is the maximum number of bytes read from the body of a
+	// discarded response to allow reusing the connection.
+	retryDrainLimit = 4 << 10
+)
+
+// retryTransport is a http.RoundTripper that retries requests failed by
+// transient errors. Requests are retried on network errors and the status
+// codes 429, 502, 503 and 504 with exponential backoff and jitter, or the
+// delay requested by the `Retry-After` header.
+//
+// Only idempotent requests are retried, POST and PATCH requests only if they
+// set an `Idempotency-Key` header. Requests with a body are only retried if
+// the body can be reset by `GetBody`.
+type retryTransport struct {
+	next        http.RoundTripper
+	maxAttempts int
+	maxTime     time.Duration
+	delay       time.Duration
+	maxDelay    time.Duration
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.maxAttempts <= 1 || !retryableRequest(req) {
+		return t.next.RoundTrip(req)
+	}
+
+	deadline := time.Now().Add(t.maxTime)
+
+	for attempt := 1; ; attempt++ {
+		res, err := t.next.RoundTrip(req)
+		if attempt >= t.maxAttempts || !retryableResponse(res, err) {
+			return res, err
+		}
+
+		delay := t.backoff(attempt)
+		if after, ok := retryAfter(res, time.Now()); ok {
+			delay = after
+		}
+
+		if time.Now().Add(delay).After(deadline) {
+			return res, err
+		}
+
+		status := 0
+		if res != nil {
+			status = res.StatusCode
+
+			_, _ = io.CopyN(io.Discard, res.Body, retryDrainLimit)
+			_ = res.Body.Close()
+		}
+
+		log.Warn().
+			Err(err).
+			Int("status", status).
+			Int("attempt", attempt).
+			Dur("delay", delay).
+			Str("method", req.Method).
+			Str("url", req.URL.Redacted()).
+			Msg("retry network request")
+
+		if err := sleepContext(req.Context(), delay); err != nil {
+			return nil, err
+		}
+
+		if req, err = rewindRequest(req); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// backoff returns the exponential delay before the next attempt with equal
+// jitter, i.e. a random delay between the half and the full delay.
+func (t *retryTransport) backoff(attempt int) time.Duration {
+	delay := t.delay
+	for i := 1; i < attempt && delay > 0 && delay < t.maxDelay; i++ {
+		delay *= 2
+	}
+
+	delay = min(delay, t.maxDelay)
+	if delay <= 0 {
+		return 0
+	}
+
+	half := delay / 2 //nolint:mnd
+
+	return half + rand.N(delay-half+1) //nolint:gosec
+}
+
+func retryableRequest(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	case http.MethodPost, http.MethodPatch:
+		return req.Header.Get("Idempotency-Key") != "" || req.Header.Get("X-Idempotency-Key") != ""
+	}
+
+	return false
+}
+
+func retryableResponse(res *http.Response, err error) bool {
+	if err != nil {
+		var certErr *tls.CertificateVerificationError
+
+		var unknownAuthErr x509.UnknownAuthorityError
+
+		switch {
+		case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded),
+			errors.Is(err, ErrDryRunRequest), errors.As(err, &certErr), errors.As(err, &unknownAuthErr):
+			return false
+		}
+
+		return true
+	}
+
+	switch res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+// retryAfter returns the delay requested by the `Retry-After` header, either
+// in seconds or as HTTP date.
+func retryAfter(res *http.Response, now time.Time) (time.Duration, bool) {
+	if res == nil {
+		return 0, false
+	}
+
+	value := res.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	return max(date.Sub(now), 0), true
+}
+
+// rewindRequest returns a copy of the request with a fresh body for the next
+// attempt.
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	if req.GetBody == nil || req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+
+	clone := req.Clone(req.Context())
+	clone.Body = body
+
+	return clone, nil
+}
+
+func sleepContext(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,245 @@
+package plugin
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		header       http.Header
+		failures     int
+		status       int
+		retryAfter   string
+		maxAttempts  int
+		wantStatus   int
+		wantAttempts int32
+	}{
+		{
+			name:         "success",
+			method:       http.MethodGet,
+			wantStatus:   http.StatusOK,
+			wantAttempts: 1,
+		},
+		{
+			name:         "retry until success",
+			method:       http.MethodGet,
+			failures:     2,
+			status:       http.StatusServiceUnavailable,
+			wantStatus:   http.StatusOK,
+			wantAttempts: 3,
+		},
+		{
+			name:         "max attempts",
+			method:       http.MethodGet,
+			failures:     5,
+			status:       http.StatusBadGateway,
+			wantStatus:   http.StatusBadGateway,
+			wantAttempts: 3,
+		},
+		{
+			name:         "retries disabled",
+			method:       http.MethodGet,
+			failures:     1,
+			status:       http.StatusBadGateway,
+			maxAttempts:  1,
+			wantStatus:   http.StatusBadGateway,
+			wantAttempts: 1,
+		},
+		{
+			name:         "not retryable status",
+			method:       http.MethodGet,
+			failures:     1,
+			status:       http.StatusInternalServerError,
+			wantStatus:   http.StatusInternalServerError,
+			wantAttempts: 1,
+		},
+		{
+			name:         "post without idempotency key",
+			method:       http.MethodPost,
+			failures:     1,
+			status:       http.StatusServiceUnavailable,
+			wantStatus:   http.StatusServiceUnavailable,
+			wantAttempts: 1,
+		},
+		{
+			name:         "post with idempotency key",
+			method:       http.MethodPost,
+			header:       http.Header{"Idempotency-Key": {"key"}},
+			failures:     1,
+			status:       http.StatusServiceUnavailable,
+			wantStatus:   http.StatusOK,
+			wantAttempts: 2,
+		},
+		{
+			name:         "retry after",
+			method:       http.MethodPut,
+			failures:     1,
+			status:       http.StatusTooManyRequests,
+			retryAfter:   "0",
+			wantStatus:   http.StatusOK,
+			wantAttempts: 2,
+		},
+		{
+			name:         "retry after exceeds max time",
+			method:       http.MethodGet,
+			failures:     1,
+			status:       http.StatusTooManyRequests,
+			retryAfter:   "120",
+			wantStatus:   http.StatusTooManyRequests,
+			wantAttempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				assert.Equal(t, "body", string(body))
+
+				if int(attempts.Add(1)) <= tt.failures {
+					if tt.retryAfter != "" {
+						w.Header().Set("Retry-After", tt.retryAfter)
+					}
+
+					w.WriteHeader(tt.status)
+
+					return
+				}
+
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			maxAttempts := tt.maxAttempts
+			if maxAttempts == 0 {
+				maxAttempts = RetryMaxAttempts
+			}
+
+			client := &http.Client{
+				Transport: &retryTransport{
+					next:        http.DefaultTransport,
+					maxAttempts: maxAttempts,
+					maxTime:     time.Second,
+					delay:       time.Millisecond,
+					maxDelay:    10 * time.Millisecond,
+				},
+			}
+
+			req, err := http.NewRequestWithContext(t.Context(), tt.method, server.URL, strings.NewReader("body"))
+			require.NoError(t, err)
+
+			for key, values := range tt.header {
+				req.Header[key] = values
+			}
+
+			res, err := client.Do(req)
+			require.NoError(t, err)
+			res.Body.Close()
+
+			assert.Equal(t, tt.wantStatus, res.StatusCode)
+			assert.Equal(t, tt.wantAttempts, attempts.Load())
+		})
+	}
+}
+
+func TestRetryTransportNetworkError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.Close()
+
+	var attempts atomic.Int32
+
+	client := &http.Client{
+		Transport: &retryTransport{
+			next: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				attempts.Add(1)
+
+				return http.DefaultTransport.RoundTrip(req)
+			}),
+			maxAttempts: 2,
+			maxTime:     time.Second,
+			delay:       time.Millisecond,
+			maxDelay:    time.Millisecond,
+		},
+	}
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	_, err = client.Do(req) //nolint:bodyclose
+	assert.Error(t, err)
+	assert.Equal(t, int32(2), attempts.Load())
+}
+
+func TestRetryTransportBackoff(t *testing.T) {
+	transport := &retryTransport{delay: time.Second, maxDelay: 5 * time.Second}
+
+	tests := []struct {
+		attempt int
+		min     time.Duration
+		max     time.Duration
+	}{
+		{attempt: 1, min: 500 * time.Millisecond, max: time.Second},
+		{attempt: 2, min: time.Second, max: 2 * time.Second},
+		{attempt: 3, min: 2 * time.Second, max: 4 * time.Second},
+		{attempt: 10, min: 2500 * time.Millisecond, max: 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		got := transport.backoff(tt.attempt)
+
+		assert.GreaterOrEqual(t, got, tt.min)
+		assert.LessOrEqual(t, got, tt.max)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		value  string
+		want   time.Duration
+		wantOk bool
+	}{
+		{name: "unset"},
+		{name: "seconds", value: "30", want: 30 * time.Second, wantOk: true},
+		{name: "date", value: "Mon, 01 Jan 2024 12:01:00 GMT", want: time.Minute, wantOk: true},
+		{name: "past date", value: "Mon, 01 Jan 2024 11:00:00 GMT", wantOk: true},
+		{name: "invalid", value: "soon"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := &http.Response{Header: http.Header{}}
+			if tt.value != "" {
+				res.Header.Set("Retry-After", tt.value)
+			}
+
+			got, ok := retryAfter(res, now)
+
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
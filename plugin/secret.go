@@ -0,0 +1,168 @@
+package plugin
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+	"github.com/urfave/cli/v3"
+)
+
+const (
+	// SecretMask replaces secret values in logs, command traces and reports.
+	SecretMask = "********"
+
+	// SecretMinLength is the minimum length of secret values. Shorter values
+	// like `1` or `no` would redact unrelated output, they are not redacted and
+	// a warning is logged instead.
+	SecretMinLength = 4
+)
+
+// Secrets is a registry of sensitive values that are redacted from all output
+// of the plugin. It is safe for concurrent use.
+type Secrets struct {
+	mu       sync.RWMutex
+	values   map[string]struct{}
+	replacer *strings.Replacer
+}
+
+// redactWriter redacts secrets from everything written to the underlying writer.
+type redactWriter struct {
+	out     io.Writer
+	secrets *Secrets
+}
+
+// NewSecrets creates an empty secret registry.
+func NewSecrets() *Secrets {
+	return &Secrets{
+		values: make(map[string]struct{}),
+	}
+}
+
+// Add registers the given secret values. Besides the plain value, its base64
+// and URL-encoded variants and its JSON string escaping are registered as well.
+// Non-empty values shorter than SecretMinLength are not registered and logged
+// as warning, as they are not redacted.
+func (s *Secrets) Add(values ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, value := range values {
+		if len(value) < SecretMinLength {
+			if value != "" {
+				log.Warn().
+					Int("length", len(value)).
+					Int("min-length", SecretMinLength).
+					Msg("secret value is too short to be redacted")
+			}
+
+			continue
+		}
+
+		for _, variant := range secretVariants(value) {
+			s.values[variant] = struct{}{}
+		}
+	}
+
+	// Longer values are replaced first to not leave parts of secrets that
+	// contain other secrets.
+	keys := make([]string, 0, len(s.values))
+	for value := range s.values {
+		keys = append(keys, value)
+	}
+
+	slices.SortFunc(keys, func(a, b string) int {
+		if len(a) != len(b) {
+			return len(b) - len(a)
+		}
+
+		return strings.Compare(a, b)
+	})
+
+	oldnew := make([]string, 0, 2*len(keys)) //nolint:mnd
+	for _, key := range keys {
+		oldnew = append(oldnew, key, SecretMask)
+	}
+
+	s.replacer = strings.NewReplacer(oldnew...)
+}
+
+// Redact replaces all registered secrets in the given string with SecretMask.
+func (s *Secrets) Redact(str string) string {
+	if s == nil {
+		return str
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.replacer == nil {
+		return str
+	}
+
+	return s.replacer.Replace(str)
+}
+
+// Writer returns a writer that redacts all registered secrets before writing to
+// the given writer. Secrets are only redacted within a single write call.
+func (s *Secrets) Writer(out io.Writer) io.Writer {
+	return &redactWriter{out: out, secrets: s}
+}
+
+func (w *redactWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(w.out, w.secrets.Redact(string(p))); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// addFlags registers the values of the given flags as secrets. Only string,
+// string slice and string map flags are supported, values of other flag types
+// are ignored.
+func (s *Secrets) addFlags(cmd *cli.Command, names []string) {
+	for _, name := range names {
+		switch value := cmd.Value(name).(type) {
+		case nil:
+		case string:
+			s.Add(value)
+		case []string:
+			s.Add(value...)
+		case map[string]string:
+			for _, v := range value {
+				s.Add(v)
+			}
+		case map[string]map[string]string:
+			for _, m := range value {
+				for _, v := range m {
+					s.Add(v)
+				}
+			}
+		default:
+			log.Warn().Str("flag", name).Msgf("ignoring secret flag of type %T", value)
+		}
+	}
+}
+
+func secretVariants(value string) []string {
+	variants := []string{
+		value,
+		base64.StdEncoding.EncodeToString([]byte(value)),
+		base64.RawStdEncoding.EncodeToString([]byte(value)),
+		base64.URLEncoding.EncodeToString([]byte(value)),
+		base64.RawURLEncoding.EncodeToString([]byte(value)),
+		url.QueryEscape(value),
+		url.PathEscape(value),
+	}
+
+	if data, err := json.Marshal(value); err == nil {
+		variants = append(variants, strings.Trim(string(data), `"`))
+	}
+
+	return variants
+}
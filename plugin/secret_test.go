@@ -0,0 +1,158 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v3"
+)
+
+func TestSecretsRedact(t *testing.T) {
+	secrets := NewSecrets()
+	secrets.Add("p@ss w/rd", "", "token")
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "plain",
+			input: "login with p@ss w/rd",
+			want:  "login with ********",
+		},
+		{
+			name:  "base64",
+			input: "Authorization: Basic " + base64.StdEncoding.EncodeToString([]byte("p@ss w/rd")),
+			want:  "Authorization: Basic ********",
+		},
+		{
+			name:  "raw url base64",
+			input: "value=" + base64.RawURLEncoding.EncodeToString([]byte("p@ss w/rd")),
+			want:  "value=********",
+		},
+		{
+			name:  "query escaped",
+			input: "https://example.com/?password=" + url.QueryEscape("p@ss w/rd"),
+			want:  "https://example.com/?password=********",
+		},
+		{
+			name:  "path escaped",
+			input: "https://example.com/" + url.PathEscape("p@ss w/rd"),
+			want:  "https://example.com/********",
+		},
+		{
+			name:  "multiple",
+			input: "token and p@ss w/rd",
+			want:  "******** and ********",
+		},
+		{
+			name:  "no secret",
+			input: "nothing to hide",
+			want:  "nothing to hide",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, secrets.Redact(tt.input))
+		})
+	}
+}
+
+func TestSecretsShortValues(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := log.Logger
+	log.Logger = zerolog.New(&buf)
+
+	t.Cleanup(func() { log.Logger = logger })
+
+	secrets := NewSecrets()
+	secrets.Add("", "1", "abc", "true")
+
+	assert.Equal(t, "1 abc ********", secrets.Redact("1 abc true"))
+	assert.Equal(t, 2, strings.Count(buf.String(), "secret value is too short to be redacted"))
+	assert.NotContains(t, buf.String(), "abc")
+}
+
+func TestSecretsRedactNil(t *testing.T) {
+	var secrets *Secrets
+
+	assert.Equal(t, "token", secrets.Redact("token"))
+	assert.Equal(t, "token", NewSecrets().Redact("token"))
+}
+
+func TestSecretsWriter(t *testing.T) {
+	secrets := NewSecrets()
+	secrets.Add(`s3cr3t"quoted`)
+
+	var buf bytes.Buffer
+
+	logger := zerolog.New(secrets.Writer(&buf))
+	logger.Info().Str("token", `s3cr3t"quoted`).Msg("login")
+
+	assert.Equal(t, `{"level":"info","token":"********","message":"login"}`+"\n", buf.String())
+}
+
+func TestPluginSecretFlags(t *testing.T) {
+	t.Setenv("PLUGIN_TOKEN", "s3cr3t")
+	t.Setenv("PLUGIN_PASSWORDS", "one1,two2")
+
+	var p *Plugin
+
+	p = New(Options{
+		Name: "dummy",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "token",
+				Sources: cli.EnvVars("PLUGIN_TOKEN"),
+			},
+			&cli.StringSliceFlag{
+				Name:    "passwords",
+				Sources: cli.EnvVars("PLUGIN_PASSWORDS"),
+			},
+		},
+		SecretFlags: []string{"token", "passwords"},
+		Execute: func(_ context.Context) error {
+			p.Report.Paragraph("token %s, passwords %v", p.App.String("token"), p.App.StringSlice("passwords"))
+
+			return fmt.Errorf("%w: request with %s", errDummy, p.App.String("token"))
+		},
+	})
+
+	result := p.RunContext(t.Context(), []string{"dummy"})
+
+	assert.Equal(t, "token ********, passwords [******** ********]\n", p.Report.String())
+	assert.Equal(t, errDummy.Error()+": request with ********", result.Reason)
+}
+
+func TestPluginSecretFlagsIgnoreNonString(t *testing.T) {
+	t.Setenv("PLUGIN_TOKEN", "s3cr3t")
+	t.Setenv("PLUGIN_VERBOSE", "true")
+	t.Setenv("PLUGIN_RETRIES", "1234")
+
+	p := New(Options{
+		Name: "dummy",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "token", Sources: cli.EnvVars("PLUGIN_TOKEN")},
+			&cli.BoolFlag{Name: "verbose", Sources: cli.EnvVars("PLUGIN_VERBOSE")},
+			&cli.IntFlag{Name: "retries", Sources: cli.EnvVars("PLUGIN_RETRIES")},
+		},
+		SecretFlags: []string{"token", "verbose", "retries"},
+		Execute:     func(_ context.Context) error { return nil },
+	})
+
+	result := p.RunContext(t.Context(), []string{"dummy"})
+
+	assert.Equal(t, StatusSuccess, result.Status)
+	assert.Equal(t, "******** true 1234", p.Secrets.Redact("s3cr3t true 1234"))
+}
@@ -0,0 +1,99 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// DefaultShutdownTimeout is the grace period the execute function gets to
+	// clean up after a termination signal was received.
+	DefaultShutdownTimeout = 10 * time.Second
+
+	// exitCodeSignalBase follows the shell convention of reporting a
+	// termination by signal as 128 + signal number.
+	exitCodeSignalBase = 128
+)
+
+// SignalError is used as cancellation cause of the plugin context if the
+// process received a termination signal.
+type SignalError struct {
+	Signal os.Signal
+}
+
+func (e *SignalError) Error() string {
+	return fmt.Sprintf("received signal: %s", e.Signal)
+}
+
+// ExitCode returns the conventional exit code for a process terminated by
+// the signal.
+func (e *SignalError) ExitCode() int {
+	if sig, ok := e.Signal.(syscall.Signal); ok {
+		return exitCodeSignalBase + int(sig)
+	}
+
+	return 1
+}
+
+// notifyContext returns a copy of the parent context that is cancelled with a
+// SignalError as cause once SIGINT or SIGTERM is received. If the returned stop
+// function is not called within the given timeout after the signal, or a second
+// signal is received, exit is called with the exit code of the signal.
+func notifyContext(
+	parent context.Context,
+	timeout time.Duration,
+	exit func(code int),
+) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(parent)
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		var sig os.Signal
+
+		select {
+		case sig = <-signals:
+		case <-done:
+			return
+		}
+
+		sigErr := &SignalError{Signal: sig}
+
+		log.Warn().
+			Str("signal", sig.String()).
+			Dur("timeout", timeout).
+			Msg("received signal, shutting down")
+
+		cancel(sigErr)
+
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+
+		select {
+		case <-done:
+			return
+		case <-timer.C:
+			log.Error().Str("signal", sig.String()).Msg("graceful shutdown timed out, forcing exit")
+		case sig = <-signals:
+			log.Error().Str("signal", sig.String()).Msg("received second signal, forcing exit")
+		}
+
+		exit(sigErr.ExitCode())
+	}()
+
+	stop := func() {
+		signal.Stop(signals)
+		close(done)
+		cancel(context.Canceled)
+	}
+
+	return ctx, stop
+}
@@ -0,0 +1,99 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignalErrorExitCode(t *testing.T) {
+	tests := []struct {
+		name   string
+		signal os.Signal
+		want   int
+	}{
+		{
+			name:   "interrupt",
+			signal: syscall.SIGINT,
+			want:   130,
+		},
+		{
+			name:   "terminate",
+			signal: syscall.SIGTERM,
+			want:   143,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := &SignalError{Signal: tt.signal}
+			assert.Equal(t, tt.want, err.ExitCode())
+		})
+	}
+}
+
+func TestNotifyContext(t *testing.T) {
+	t.Run("cancel on signal", func(t *testing.T) {
+		exitCode := make(chan int, 1)
+
+		ctx, stop := notifyContext(t.Context(), 50*time.Millisecond, func(code int) {
+			exitCode <- code
+		})
+		defer stop()
+
+		sendSignal(t, syscall.SIGTERM)
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+			t.Fatal("context not cancelled after signal")
+		}
+
+		var sigErr *SignalError
+		assert.True(t, errors.As(context.Cause(ctx), &sigErr))
+		assert.Equal(t, syscall.SIGTERM, sigErr.Signal)
+
+		select {
+		case code := <-exitCode:
+			assert.Equal(t, 143, code)
+		case <-time.After(time.Second):
+			t.Fatal("exit not called after shutdown timeout")
+		}
+	})
+
+	t.Run("no exit after stop", func(t *testing.T) {
+		exitCode := make(chan int, 1)
+
+		ctx, stop := notifyContext(t.Context(), time.Second, func(code int) {
+			exitCode <- code
+		})
+
+		sendSignal(t, syscall.SIGINT)
+		<-ctx.Done()
+		stop()
+
+		select {
+		case code := <-exitCode:
+			t.Fatalf("unexpected exit with code %d", code)
+		case <-time.After(100 * time.Millisecond):
+		}
+	})
+}
+
+func sendSignal(t *testing.T, sig os.Signal) {
+	t.Helper()
+
+	proc, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := proc.Signal(sig); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -22,19 +22,33 @@ import (
 
 // Step defines runtime metadata for a step.
 type Step struct {
-	Number   int64
-	Started  time.Time
-	Finished time.Time
+	Name     string    `json:"name"`
+	Number   int64     `json:"number"`
+	URL      string    `json:"url"`
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished"`
 }
 
 func stepFlags(category string) []cli.Flag {
 	return []cli.Flag{
+		&cli.StringFlag{
+			Name:     "step.name",
+			Usage:    "step name",
+			Sources:  cli.EnvVars("CI_STEP_NAME"),
+			Category: category,
+		},
 		&cli.Int64Flag{
 			Name:     "step.number",
 			Usage:    "step number",
 			Sources:  cli.EnvVars("CI_STEP_NUMBER"),
 			Category: category,
 		},
+		&cli.StringFlag{
+			Name:     "step.url",
+			Usage:    "step url",
+			Sources:  cli.EnvVars("CI_STEP_URL"),
+			Category: category,
+		},
 		&cli.Int64Flag{
 			Name:     "step.started",
 			Usage:    "step start time",
@@ -52,8 +66,10 @@ func stepFlags(category string) []cli.Flag {
 
 func stepFromContext(c *cli.Command) Step {
 	return Step{
+		Name:     c.String("step.name"),
 		Number:   c.Int64("step.number"),
-		Started:  time.Unix(c.Int64("step.started"), 0),
-		Finished: time.Unix(c.Int64("step.finished"), 0),
+		URL:      c.String("step.url"),
+		Started:  unixTime(c.Int64("step.started")),
+		Finished: unixTime(c.Int64("step.finished")),
 	}
 }
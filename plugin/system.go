@@ -20,11 +20,11 @@ import (
 
 // System defines runtime metadata for a ci/cd system.
 type System struct {
-	Name     string
-	Host     string
-	URL      string
-	Platform string
-	Version  string
+	Name     string `json:"name"`
+	Host     string `json:"host"`
+	URL      string `json:"url"`
+	Platform string `json:"platform"`
+	Version  string `json:"version"`
 }
 
 func systemFlags(category string) []cli.Flag {
@@ -0,0 +1,102 @@
+package plugin
+
+import (
+	"time"
+)
+
+// unixTime converts a Unix timestamp to a time. Unset timestamps, zero or
+// negative, result in the zero time instead of the Unix epoch.
+func unixTime(sec int64) time.Time {
+	if sec <= 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(sec, 0)
+}
+
+// FormatTime formats the time with the layout in the given IANA time zone,
+// e.g. `Europe/Berlin`. The layout defaults to time.RFC3339 and the zone to UTC.
+// The zero time is formatted as empty string. Zones are loaded from the time
+// zone database of the system, plugins running in images without it can
+// import time/tzdata.
+func FormatTime(t time.Time, layout, zone string) (string, error) {
+	if t.IsZero() {
+		return "", nil
+	}
+
+	if layout == "" {
+		layout = time.RFC3339
+	}
+
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		return "", err
+	}
+
+	return t.In(loc).Format(layout), nil
+}
+
+// elapsed returns the duration from start to finish. If finish is unset, the
+// duration up to now is returned. It is zero if start is unset.
+func elapsed(start, finish, now time.Time) time.Duration {
+	if start.IsZero() {
+		return 0
+	}
+
+	if finish.IsZero() {
+		finish = now
+	}
+
+	if finish.Before(start) {
+		return 0
+	}
+
+	return finish.Sub(start).Round(time.Second)
+}
+
+// QueueTime returns the duration the pipeline waited from creation until it
+// started. It is zero if one of the times is unset.
+func (p Pipeline) QueueTime() time.Duration {
+	if p.Created.IsZero() || p.Started.IsZero() {
+		return 0
+	}
+
+	return elapsed(p.Created, p.Started, p.Started)
+}
+
+// Elapsed returns the run time of the pipeline. For a running pipeline, it is
+// the run time so far.
+func (p Pipeline) Elapsed() time.Duration {
+	return elapsed(p.Started, p.Finished, time.Now())
+}
+
+// FormatCreated formats the creation time, see FormatTime.
+func (p Pipeline) FormatCreated(layout, zone string) (string, error) {
+	return FormatTime(p.Created, layout, zone)
+}
+
+// FormatStarted formats the start time, see FormatTime.
+func (p Pipeline) FormatStarted(layout, zone string) (string, error) {
+	return FormatTime(p.Started, layout, zone)
+}
+
+// FormatFinished formats the finish time, see FormatTime.
+func (p Pipeline) FormatFinished(layout, zone string) (string, error) {
+	return FormatTime(p.Finished, layout, zone)
+}
+
+// Elapsed returns the run time of the step. For a running step, it is the run
+// time so far.
+func (s Step) Elapsed() time.Duration {
+	return elapsed(s.Started, s.Finished, time.Now())
+}
+
+// FormatStarted formats the start time, see FormatTime.
+func (s Step) FormatStarted(layout, zone string) (string, error) {
+	return FormatTime(s.Started, layout, zone)
+}
+
+// FormatFinished formats the finish time, see FormatTime.
+func (s Step) FormatFinished(layout, zone string) (string, error) {
+	return FormatTime(s.Finished, layout, zone)
+}
@@ -0,0 +1,153 @@
+package plugin
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thegeeklab/wp-plugin-go/v6/template"
+)
+
+func TestUnsetTimestamps(t *testing.T) {
+	got, err := fromEnv(t, map[string]string{
+		"CI_PIPELINE_CREATED": "1700000000",
+		"CI_STEP_STARTED":     "0",
+	}, metadataFromContext)
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Unix(1700000000, 0), got.Pipeline.Created)
+	assert.True(t, got.Pipeline.Started.IsZero())
+	assert.True(t, got.Pipeline.Finished.IsZero())
+	assert.True(t, got.PrevPipeline.Created.IsZero())
+	assert.True(t, got.Step.Started.IsZero())
+	assert.True(t, got.Step.Finished.IsZero())
+	assert.Zero(t, got.Pipeline.QueueTime())
+	assert.Zero(t, got.Step.Elapsed())
+}
+
+func TestElapsed(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	now := start.Add(90 * time.Second)
+
+	tests := []struct {
+		name   string
+		start  time.Time
+		finish time.Time
+		want   time.Duration
+	}{
+		{
+			name:   "finished",
+			start:  start,
+			finish: start.Add(2*time.Minute + 400*time.Millisecond),
+			want:   2 * time.Minute,
+		},
+		{
+			name:  "running",
+			start: start,
+			want:  90 * time.Second,
+		},
+		{
+			name:   "not started",
+			finish: start,
+		},
+		{
+			name:   "finish before start",
+			start:  start,
+			finish: start.Add(-time.Second),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, elapsed(tt.start, tt.finish, now))
+		})
+	}
+}
+
+func TestPipelineDurations(t *testing.T) {
+	p := Pipeline{
+		Created:  time.Unix(1700000000, 0),
+		Started:  time.Unix(1700000030, 0),
+		Finished: time.Unix(1700000150, 0),
+	}
+
+	assert.Equal(t, 30*time.Second, p.QueueTime())
+	assert.Equal(t, 2*time.Minute, p.Elapsed())
+	assert.Equal(t, 2*time.Minute, Step{Started: p.Started, Finished: p.Finished}.Elapsed())
+
+	running := Step{Started: time.Now().Add(-time.Minute)}
+	assert.GreaterOrEqual(t, running.Elapsed(), time.Minute)
+}
+
+func TestFormatTime(t *testing.T) {
+	ts := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name    string
+		time    time.Time
+		layout  string
+		zone    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+			time: ts,
+			want: "2023-11-14T22:13:20Z",
+		},
+		{
+			name:   "layout and zone",
+			time:   ts,
+			layout: "2006-01-02 15:04 MST",
+			zone:   "Europe/Berlin",
+			want:   "2023-11-14 23:13 CET",
+		},
+		{
+			name: "zero time",
+		},
+		{
+			name:    "unknown zone",
+			time:    ts,
+			zone:    "Mars/Olympus",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FormatTime(tt.time, tt.layout, tt.zone)
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestTimeTemplate(t *testing.T) {
+	m := Metadata{
+		Pipeline: Pipeline{
+			Created:  time.Unix(1700000000, 0),
+			Started:  time.Unix(1700000030, 0),
+			Finished: time.Unix(1700000150, 0),
+		},
+	}
+
+	tmpl := strings.Join([]string{
+		`{{ .Pipeline.FormatStarted "15:04" "Europe/Berlin" }}`,
+		`{{ .Pipeline.QueueTime }}`,
+		`{{ .Pipeline.Elapsed }}`,
+		`{{ .Step.FormatStarted "" "" }}`,
+	}, "|")
+
+	got, err := template.RenderTrim(t.Context(), http.Client{}, tmpl, m)
+	assert.NoError(t, err)
+	assert.Equal(t, "23:13|30s|2m0s|", got)
+}
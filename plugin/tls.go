@@ -0,0 +1,170 @@
+package plugin
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/urfave/cli/v3"
+)
+
+var (
+	ErrInvalidCACert     = errors.New("invalid CA certificate")
+	ErrInvalidClientCert = errors.New("invalid client certificate")
+	ErrInvalidTLSVersion = errors.New("invalid TLS version")
+)
+
+func tlsFlags(category string) []cli.Flag {
+	return []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:     "transport.ca-cert",
+			Usage:    "additional CA certificates to trust, as file paths or PEM encoded certificates",
+			Sources:  cli.EnvVars("PLUGIN_CA_CERT"),
+			Category: category,
+		},
+		&cli.StringSliceFlag{
+			Name:     "transport.client-cert",
+			Usage:    "client certificates for mutual TLS, as file paths or PEM encoded certificates",
+			Sources:  cli.EnvVars("PLUGIN_CLIENT_CERT"),
+			Category: category,
+		},
+		&cli.StringSliceFlag{
+			Name:     "transport.client-key",
+			Usage:    "private keys of the client certificates in the same order, as file paths or PEM encoded keys",
+			Sources:  cli.EnvVars("PLUGIN_CLIENT_KEY"),
+			Category: category,
+		},
+		&cli.StringFlag{
+			Name:     "transport.tls-min-version",
+			Usage:    "minimum TLS version, one of 1.0, 1.1, 1.2 or 1.3",
+			Sources:  cli.EnvVars("PLUGIN_TLS_MIN_VERSION"),
+			Category: category,
+		},
+		&cli.StringFlag{
+			Name:     "transport.tls-server-name",
+			Usage:    "server name used to verify the certificate of the server instead of the host name",
+			Sources:  cli.EnvVars("PLUGIN_TLS_SERVER_NAME"),
+			Category: category,
+		},
+	}
+}
+
+// tlsConfigFromContext creates the TLS configuration of the network flags.
+func tlsConfigFromContext(cmd *cli.Command) (*tls.Config, error) {
+	certs := systemCertPool()
+
+	for _, value := range cmd.StringSlice("transport.ca-cert") {
+		data, err := readPEM(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidCACert, err)
+		}
+
+		if !certs.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("%w: no PEM encoded certificate found in %s", ErrInvalidCACert, pemSource(value))
+		}
+	}
+
+	clientCerts, err := loadClientCerts(cmd.StringSlice("transport.client-cert"), cmd.StringSlice("transport.client-key"))
+	if err != nil {
+		return nil, err
+	}
+
+	minVersion, err := parseTLSVersion(cmd.String("transport.tls-min-version"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		RootCAs:            certs,
+		Certificates:       clientCerts,
+		MinVersion:         minVersion,
+		ServerName:         cmd.String("transport.tls-server-name"),
+		InsecureSkipVerify: cmd.Bool("transport.insecure-skip-verify"), //nolint:gosec
+	}, nil
+}
+
+func systemCertPool() *x509.CertPool {
+	certs, err := x509.SystemCertPool()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to find system CA certs")
+
+		return x509.NewCertPool()
+	}
+
+	return certs
+}
+
+func loadClientCerts(certs, keys []string) ([]tls.Certificate, error) {
+	if len(certs) != len(keys) {
+		return nil, fmt.Errorf("%w: got %d certificates but %d keys", ErrInvalidClientCert, len(certs), len(keys))
+	}
+
+	clientCerts := make([]tls.Certificate, 0, len(certs))
+
+	for i := range certs {
+		cert, err := readPEM(certs[i])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidClientCert, err)
+		}
+
+		key, err := readPEM(keys[i])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidClientCert, err)
+		}
+
+		pair, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidClientCert, pemSource(certs[i]), err)
+		}
+
+		clientCerts = append(clientCerts, pair)
+	}
+
+	return clientCerts, nil
+}
+
+// readPEM returns the value if it is PEM encoded, otherwise the content of the
+// file at the path of the value.
+func readPEM(value string) ([]byte, error) {
+	if isPEM(value) {
+		return []byte(value), nil
+	}
+
+	return os.ReadFile(value)
+}
+
+// pemSource describes the value in errors without leaking PEM content.
+func pemSource(value string) string {
+	if isPEM(value) {
+		return "PEM value"
+	}
+
+	return value
+}
+
+func isPEM(value string) bool {
+	return strings.HasPrefix(strings.TrimSpace(value), "-----BEGIN")
+}
+
+// parseTLSVersion returns the TLS version of e.g. `1.2`. An empty version
+// returns zero to use the default of crypto/tls.
+func parseTLSVersion(version string) (uint16, error) {
+	switch strings.TrimPrefix(strings.ToLower(strings.TrimSpace(version)), "tls") {
+	case "":
+		return 0, nil
+	case "1.0":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	}
+
+	return 0, fmt.Errorf("%w: %q", ErrInvalidTLSVersion, version)
+}
@@ -0,0 +1,219 @@
+package plugin
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testKeyPair returns a PEM encoded self-signed client certificate and key.
+func testKeyPair(t *testing.T) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+
+	return string(certPEM), string(keyPEM)
+}
+
+func TestNetworkTLS(t *testing.T) {
+	clientCert, clientKey := testKeyPair(t)
+
+	clientCAs := x509.NewCertPool()
+	require.True(t, clientCAs.AppendCertsFromPEM([]byte(clientCert)))
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	server.StartTLS()
+	defer server.Close()
+
+	serverCA := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	keyFile := filepath.Join(dir, "client.key")
+
+	require.NoError(t, os.WriteFile(caFile, []byte(serverCA), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, []byte(clientKey), 0o600))
+
+	tests := []struct {
+		name    string
+		envs    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "ca file and client cert",
+			envs: map[string]string{
+				"PLUGIN_CA_CERT":         caFile,
+				"PLUGIN_CLIENT_CERT":     clientCert,
+				"PLUGIN_CLIENT_KEY":      keyFile,
+				"PLUGIN_TLS_MIN_VERSION": "1.3",
+			},
+		},
+		{
+			name: "ca value and server name",
+			envs: map[string]string{
+				"PLUGIN_CA_CERT":         serverCA,
+				"PLUGIN_CLIENT_CERT":     clientCert,
+				"PLUGIN_CLIENT_KEY":      clientKey,
+				"PLUGIN_TLS_SERVER_NAME": "example.com",
+			},
+		},
+		{
+			name:    "untrusted server",
+			envs:    map[string]string{"PLUGIN_CLIENT_CERT": clientCert, "PLUGIN_CLIENT_KEY": clientKey},
+			wantErr: true,
+		},
+		{
+			name:    "missing client cert",
+			envs:    map[string]string{"PLUGIN_CA_CERT": caFile},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			network, err := fromEnv(t, tt.envs, NewNetwork)
+			require.NoError(t, err)
+
+			network.Client.Transport.(*retryTransport).maxAttempts = 1
+
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL, nil)
+			require.NoError(t, err)
+
+			res, err := network.Client.Do(req)
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			res.Body.Close()
+
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+		})
+	}
+}
+
+func TestNetworkTLSInvalid(t *testing.T) {
+	clientCert, clientKey := testKeyPair(t)
+
+	tests := []struct {
+		name    string
+		envs    map[string]string
+		wantErr error
+	}{
+		{
+			name:    "missing ca file",
+			envs:    map[string]string{"PLUGIN_CA_CERT": filepath.Join(t.TempDir(), "missing.pem")},
+			wantErr: ErrInvalidCACert,
+		},
+		{
+			name:    "invalid ca value",
+			envs:    map[string]string{"PLUGIN_CA_CERT": "-----BEGIN CERTIFICATE-----\ninvalid"},
+			wantErr: ErrInvalidCACert,
+		},
+		{
+			name:    "key missing",
+			envs:    map[string]string{"PLUGIN_CLIENT_CERT": clientCert},
+			wantErr: ErrInvalidClientCert,
+		},
+		{
+			name:    "key mismatch",
+			envs:    map[string]string{"PLUGIN_CLIENT_CERT": clientCert, "PLUGIN_CLIENT_KEY": clientCert},
+			wantErr: ErrInvalidClientCert,
+		},
+		{
+			name:    "cert missing",
+			envs:    map[string]string{"PLUGIN_CLIENT_KEY": clientKey},
+			wantErr: ErrInvalidClientCert,
+		},
+		{
+			name:    "unknown tls version",
+			envs:    map[string]string{"PLUGIN_TLS_MIN_VERSION": "2.0"},
+			wantErr: ErrInvalidTLSVersion,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := fromEnv(t, tt.envs, NewNetwork)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestParseTLSVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    uint16
+		wantErr bool
+	}{
+		{version: ""},
+		{version: "1.2", want: tls.VersionTLS12},
+		{version: "TLS1.3", want: tls.VersionTLS13},
+		{version: "1.4", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseTLSVersion(tt.version)
+		if tt.wantErr {
+			assert.ErrorIs(t, err, ErrInvalidTLSVersion)
+
+			continue
+		}
+
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, got)
+	}
+}
+
+func TestPluginTLSConfigError(t *testing.T) {
+	t.Setenv("PLUGIN_TLS_MIN_VERSION", "2.0")
+
+	result := New(Options{Name: "dummy", Execute: func(_ context.Context) error { return nil }}).
+		RunContext(t.Context(), []string{"dummy"})
+
+	assert.Equal(t, StatusFailure, result.Status)
+	assert.Equal(t, CategoryConfig, result.Category)
+}
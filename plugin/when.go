@@ -0,0 +1,225 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/thegeeklab/wp-plugin-go/v6/file"
+	"github.com/urfave/cli/v3"
+)
+
+var ErrInvalidWhen = errors.New("invalid when rules")
+
+type (
+	// When defines the conditions the plugin runs under. The plugin runs if any
+	// of the rules matches. An empty list always matches.
+	When []Rule
+
+	// Rule defines the constraints of a when rule. A rule matches if all of its
+	// constraints match, unset constraints always match.
+	Rule struct {
+		Branch       Constraint `json:"branch"`
+		Tag          Constraint `json:"tag"`
+		Event        Constraint `json:"event"`
+		Ref          Constraint `json:"ref"`
+		Path         Constraint `json:"path"`
+		DeployTarget Constraint `json:"deploy_target"`
+	}
+
+	// Constraint defines include and exclude glob patterns, see file.Match. A
+	// value matches if it matches any include pattern, or no include patterns
+	// are set, and none of the exclude patterns.
+	//
+	// In JSON a constraint is either a single pattern, a list of include
+	// patterns or an object with include and exclude lists.
+	Constraint struct {
+		Include []string `json:"include"`
+		Exclude []string `json:"exclude"`
+	}
+)
+
+func whenFlags(category string) []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:     "when",
+			Usage:    "JSON encoded rules to decide whether the plugin runs, e.g. `{\"branch\":\"main\"}`",
+			Sources:  cli.EnvVars("PLUGIN_WHEN"),
+			Category: category,
+		},
+	}
+}
+
+// ParseWhen decodes when rules from JSON. A single rule object is accepted as
+// well as a list of rules.
+func ParseWhen(data string) (When, error) {
+	data = strings.TrimSpace(data)
+	if data == "" {
+		return nil, nil
+	}
+
+	var when When
+
+	if strings.HasPrefix(data, "{") {
+		var rule Rule
+
+		if err := decodeStrict(data, &rule); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidWhen, err)
+		}
+
+		return When{rule}, nil
+	}
+
+	if err := decodeStrict(data, &when); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidWhen, err)
+	}
+
+	return when, nil
+}
+
+func decodeStrict(data string, v any) error {
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	return decoder.Decode(v)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (c *Constraint) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+
+	switch {
+	case bytes.HasPrefix(data, []byte(`"`)):
+		var pattern string
+
+		if err := json.Unmarshal(data, &pattern); err != nil {
+			return err
+		}
+
+		c.Include = []string{pattern}
+
+		return nil
+	case bytes.HasPrefix(data, []byte("[")):
+		return json.Unmarshal(data, &c.Include)
+	}
+
+	type constraint Constraint
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	return decoder.Decode((*constraint)(c))
+}
+
+// IsEmpty reports whether the constraint has no patterns.
+func (c Constraint) IsEmpty() bool {
+	return len(c.Include) == 0 && len(c.Exclude) == 0
+}
+
+// Match reports whether the value matches the constraint.
+func (c Constraint) Match(value string) (bool, error) {
+	matched, err := file.MatchPaths([]string{value}, c.Include, c.Exclude)
+	if err != nil {
+		return false, err
+	}
+
+	return len(matched) > 0, nil
+}
+
+// Match reports whether any of the rules matches the metadata. If no rule
+// matches, the reason contains the first mismatching constraint of each rule.
+func (w When) Match(m Metadata) (bool, string, error) {
+	if len(w) == 0 {
+		return true, "", nil
+	}
+
+	reasons := make([]string, 0, len(w))
+
+	for _, rule := range w {
+		ok, reason, err := rule.Match(m)
+		if err != nil || ok {
+			return ok, "", err
+		}
+
+		reasons = append(reasons, reason)
+	}
+
+	return false, strings.Join(reasons, "; "), nil
+}
+
+// Match reports whether all constraints of the rule match the metadata. If a
+// constraint does not match, the reason names it. The path constraint matches
+// if any changed file matches it. It is ignored if the changed files are
+// unknown.
+func (r Rule) Match(m Metadata) (bool, string, error) {
+	values := []struct {
+		name       string
+		constraint Constraint
+		value      string
+	}{
+		{name: "event", constraint: r.Event, value: string(m.Pipeline.Event)},
+		{name: "branch", constraint: r.Branch, value: m.Curr.Branch},
+		{name: "tag", constraint: r.Tag, value: m.Curr.Tag},
+		{name: "ref", constraint: r.Ref, value: m.Curr.Ref},
+		{name: "deploy target", constraint: r.DeployTarget, value: m.Pipeline.DeployTarget},
+	}
+
+	for _, v := range values {
+		if v.constraint.IsEmpty() {
+			continue
+		}
+
+		ok, err := v.constraint.Match(v.value)
+		if err != nil {
+			return false, "", fmt.Errorf("%w: %s: %w", ErrInvalidWhen, v.name, err)
+		}
+
+		if !ok {
+			return false, fmt.Sprintf("%s %q does not match", v.name, v.value), nil
+		}
+	}
+
+	if r.Path.IsEmpty() {
+		return true, "", nil
+	}
+
+	_, ok, err := m.MatchChangedFiles(r.Path.Include, r.Path.Exclude)
+
+	switch {
+	case errors.Is(err, ErrChangedFilesUnknown):
+		log.Debug().Err(err).Msg("ignoring path rule")
+
+		return true, "", nil
+	case err != nil:
+		return false, "", fmt.Errorf("%w: path: %w", ErrInvalidWhen, err)
+	case !ok:
+		return false, "no changed file matches path", nil
+	}
+
+	return true, "", nil
+}
+
+// evaluateWhen returns a skip error if the when rules of the options or the
+// when flag do not match the metadata. Both must match if both are set.
+func (p *Plugin) evaluateWhen(cmd *cli.Command) error {
+	flagWhen, err := ParseWhen(cmd.String("when"))
+	if err != nil {
+		return NewError(CategoryConfig, err).WithHint("check the JSON set by PLUGIN_WHEN")
+	}
+
+	for _, when := range []When{p.when, flagWhen} {
+		ok, reason, err := when.Match(p.Metadata)
+		if err != nil {
+			return NewError(CategoryConfig, err)
+		}
+
+		if !ok {
+			return Skip("when rules do not match: %s", reason)
+		}
+	}
+
+	return nil
+}
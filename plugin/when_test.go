@@ -0,0 +1,231 @@
+package plugin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWhen(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    When
+		wantErr bool
+	}{
+		{
+			name: "empty",
+			data: " ",
+		},
+		{
+			name: "single rule",
+			data: `{"branch": "main", "event": ["push", "tag"]}`,
+			want: When{
+				{
+					Branch: Constraint{Include: []string{"main"}},
+					Event:  Constraint{Include: []string{"push", "tag"}},
+				},
+			},
+		},
+		{
+			name: "list of rules",
+			data: `[{"path": {"include": ["docs/**"], "exclude": ["docs/drafts/**"]}}, {"deploy_target": "prod*"}]`,
+			want: When{
+				{Path: Constraint{Include: []string{"docs/**"}, Exclude: []string{"docs/drafts/**"}}},
+				{DeployTarget: Constraint{Include: []string{"prod*"}}},
+			},
+		},
+		{
+			name:    "unknown constraint",
+			data:    `{"branches": "main"}`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown constraint field",
+			data:    `{"branch": {"only": "main"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid json",
+			data:    `{"branch": `,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseWhen(tt.data)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidWhen)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWhenMatch(t *testing.T) {
+	metadata := Metadata{
+		Pipeline: Pipeline{
+			Event:        EventPush,
+			DeployTarget: "production",
+			Files:        []string{"docs/index.md", "README.md"},
+		},
+		Curr: Commit{Branch: "release/v1", Ref: "refs/heads/release/v1"},
+	}
+
+	tests := []struct {
+		name       string
+		when       When
+		want       bool
+		wantReason string
+		wantErr    bool
+	}{
+		{
+			name: "no rules",
+			want: true,
+		},
+		{
+			name: "all constraints match",
+			when: When{
+				{
+					Branch:       Constraint{Include: []string{"main", "release/**"}},
+					Event:        Constraint{Include: []string{"push"}},
+					Ref:          Constraint{Include: []string{"refs/heads/**"}},
+					Path:         Constraint{Include: []string{"docs/**"}},
+					DeployTarget: Constraint{Exclude: []string{"staging"}},
+				},
+			},
+			want: true,
+		},
+		{
+			name:       "branch excluded",
+			when:       When{{Branch: Constraint{Exclude: []string{"release/*"}}}},
+			wantReason: `branch "release/v1" does not match`,
+		},
+		{
+			name:       "tag required",
+			when:       When{{Tag: Constraint{Include: []string{"v*"}}}},
+			wantReason: `tag "" does not match`,
+		},
+		{
+			name:       "path does not match",
+			when:       When{{Path: Constraint{Include: []string{"charts/**"}}}},
+			wantReason: "no changed file matches path",
+		},
+		{
+			name: "any rule matches",
+			when: When{
+				{Event: Constraint{Include: []string{"tag"}}},
+				{Branch: Constraint{Include: []string{"release/*"}}},
+			},
+			want: true,
+		},
+		{
+			name: "no rule matches",
+			when: When{
+				{Event: Constraint{Include: []string{"tag"}}},
+				{DeployTarget: Constraint{Include: []string{"staging"}}},
+			},
+			wantReason: `event "push" does not match; deploy target "production" does not match`,
+		},
+		{
+			name:    "invalid pattern",
+			when:    When{{Branch: Constraint{Include: []string{"["}}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reason, err := tt.when.Match(metadata)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidWhen)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantReason, reason)
+		})
+	}
+}
+
+func TestWhenMatchUnknownFiles(t *testing.T) {
+	ok, _, err := When{{Path: Constraint{Include: []string{"docs/**"}}}}.Match(Metadata{})
+
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestPluginWhen(t *testing.T) {
+	tests := []struct {
+		name   string
+		envs   map[string]string
+		when   When
+		want   Status
+		reason string
+	}{
+		{
+			name: "flag matches",
+			envs: map[string]string{"CI_COMMIT_BRANCH": "main", "PLUGIN_WHEN": `{"branch": "main"}`},
+			want: StatusSuccess,
+		},
+		{
+			name:   "flag does not match",
+			envs:   map[string]string{"CI_COMMIT_BRANCH": "dev", "PLUGIN_WHEN": `{"branch": "main"}`},
+			want:   StatusSkipped,
+			reason: `when rules do not match: branch "dev" does not match`,
+		},
+		{
+			name:   "options do not match",
+			envs:   map[string]string{"CI_PIPELINE_EVENT": "pull_request", "PLUGIN_WHEN": `{"event": "*"}`},
+			when:   When{{Event: Constraint{Include: []string{"push"}}}},
+			want:   StatusSkipped,
+			reason: `when rules do not match: event "pull_request" does not match`,
+		},
+		{
+			name: "invalid flag",
+			envs: map[string]string{"PLUGIN_WHEN": `{"branch": `},
+			want: StatusFailure,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.envs {
+				t.Setenv(key, value)
+			}
+
+			executed := false
+
+			p := New(Options{
+				Name: "dummy",
+				When: tt.when,
+				Execute: func(_ context.Context) error {
+					executed = true
+
+					return nil
+				},
+			})
+
+			result := p.RunContext(t.Context(), []string{"dummy"})
+
+			assert.Equal(t, tt.want, result.Status)
+			assert.Equal(t, tt.want == StatusSuccess, executed)
+
+			if tt.reason != "" {
+				assert.Equal(t, tt.reason, result.Reason)
+			}
+
+			if tt.want == StatusFailure {
+				assert.Equal(t, CategoryConfig, result.Category)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package plugin
+
+import (
+	"github.com/urfave/cli/v3"
+)
+
+// Workflow defines runtime metadata for a workflow.
+type Workflow struct {
+	Name   string `json:"name"`
+	Number int64  `json:"number"`
+}
+
+// Workspace defines runtime metadata for the workspace of a workflow.
+type Workspace struct {
+	Path string `json:"path"`
+}
+
+func workflowFlags(category string) []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:     "workflow.name",
+			Usage:    "workflow name",
+			Sources:  cli.EnvVars("CI_WORKFLOW_NAME"),
+			Category: category,
+		},
+		&cli.Int64Flag{
+			Name:     "workflow.number",
+			Usage:    "workflow number",
+			Sources:  cli.EnvVars("CI_WORKFLOW_NUMBER"),
+			Category: category,
+		},
+		&cli.StringFlag{
+			Name:     "workspace.path",
+			Usage:    "workspace path",
+			Sources:  cli.EnvVars("CI_WORKSPACE"),
+			Category: category,
+		},
+	}
+}
+
+func workflowFromContext(c *cli.Command) Workflow {
+	return Workflow{
+		Name:   c.String("workflow.name"),
+		Number: c.Int64("workflow.number"),
+	}
+}
+
+func workspaceFromContext(c *cli.Command) Workspace {
+	return Workspace{
+		Path: c.String("workspace.path"),
+	}
+}
@@ -0,0 +1,293 @@
+// Package plugintest provides a harness to run plugins built on the plugin
+// package in tests with fake metadata, captured output and stubbed commands.
+//
+// The harness modifies process-wide state like environment variables, the
+// standard output and the global logger. Tests using it must not run in parallel.
+package plugintest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/joho/godotenv"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/thegeeklab/wp-plugin-go/v6/internal/execstub"
+	"github.com/thegeeklab/wp-plugin-go/v6/plugin"
+	"github.com/urfave/cli/v3"
+)
+
+var ErrUnexpectedCommand = errors.New("unexpected command")
+
+// envPrefixes are the prefixes of environment variables that are cleared by the
+// harness to isolate the test from the environment it runs in.
+//
+//nolint:gochecknoglobals
+var envPrefixes = []string{"CI_", "CI=", "PLUGIN_", "DRONE"}
+
+// Harness runs a plugin in a controlled environment.
+type Harness struct {
+	tb       testing.TB
+	mu       sync.Mutex
+	stubs    map[string][]CommandResult
+	commands [][]string
+}
+
+// CommandResult is the scripted result of a stubbed command.
+type CommandResult struct {
+	// Output written to the stdout of the command.
+	Stdout string
+	// Output written to the stderr of the command.
+	Stderr string
+	// Exit code of the command. A non-zero exit code results in an ExitError.
+	ExitCode int
+	// Error returned by the command, takes precedence over the exit code.
+	Err error
+}
+
+// ExitError is returned by stubbed commands with a non-zero exit code.
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// ExitCode returns the exit code of the stubbed command.
+func (e *ExitError) ExitCode() int {
+	return e.Code
+}
+
+// Result is the outcome of a plugin run in the harness.
+type Result struct {
+	plugin.Result
+	// Captured standard output, including command traces.
+	Stdout string
+	// Captured standard error.
+	Stderr string
+	// Captured log output without colors and timestamps.
+	Logs string
+	// Arguments of all commands that were executed.
+	Commands [][]string
+	// Step outputs set by the plugin.
+	Outputs map[string]string
+	// Markdown formatting of the step report.
+	Report string
+}
+
+// New creates a new harness. All Woodpecker, Drone and plugin environment
+// variables are cleared for the duration of the test.
+func New(tb testing.TB) *Harness {
+	tb.Helper()
+
+	for _, kv := range os.Environ() {
+		key, _, _ := strings.Cut(kv, "=")
+
+		if slices.ContainsFunc(envPrefixes, func(prefix string) bool {
+			return strings.HasPrefix(key+"=", prefix)
+		}) {
+			tb.Setenv(key, "")
+			os.Unsetenv(key)
+		}
+	}
+
+	return &Harness{
+		tb:    tb,
+		stubs: make(map[string][]CommandResult),
+	}
+}
+
+// WithMetadata sets the Woodpecker environment variables for the given metadata.
+func (h *Harness) WithMetadata(m plugin.Metadata) *Harness {
+	h.tb.Helper()
+
+	for _, kv := range m.Environ() {
+		key, value, _ := strings.Cut(kv, "=")
+		h.tb.Setenv(key, value)
+	}
+
+	return h
+}
+
+// WithEnvFile sets the environment variables of the given dotenv file, e.g. a
+// fixture of a Woodpecker step environment.
+func (h *Harness) WithEnvFile(path string) *Harness {
+	h.tb.Helper()
+
+	env, err := godotenv.Read(path)
+	if err != nil {
+		h.tb.Fatalf("failed to read env file: %v", err)
+	}
+
+	for key, value := range env {
+		h.tb.Setenv(key, value)
+	}
+
+	return h
+}
+
+// WithEnv sets an environment variable, e.g. a plugin setting.
+func (h *Harness) WithEnv(key, value string) *Harness {
+	h.tb.Helper()
+	h.tb.Setenv(key, value)
+
+	return h
+}
+
+// StubCommand scripts the results of the command with the given name. Each call
+// of the command consumes the next result, the last result is repeated. Commands
+// without stub fail with ErrUnexpectedCommand.
+func (h *Harness) StubCommand(name string, results ...CommandResult) *Harness {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(results) == 0 {
+		results = []CommandResult{{}}
+	}
+
+	h.stubs[name] = append(h.stubs[name], results...)
+
+	return h
+}
+
+// Run runs the plugin with the given arguments and returns the result. The
+// plugin should be created after the environment of the harness is set up.
+func (h *Harness) Run(p *plugin.Plugin, args ...string) Result {
+	h.tb.Helper()
+
+	var (
+		logs   bytes.Buffer
+		result Result
+	)
+
+	restoreStub := execstub.Set(h.runCommand)
+	defer restoreStub()
+
+	logger := log.Logger
+	defer func() { log.Logger = logger }()
+
+	before := p.App.Before
+	p.App.Before = func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+		var err error
+
+		if before != nil {
+			ctx, err = before(ctx, cmd)
+		}
+
+		log.Logger = log.Output(zerolog.ConsoleWriter{
+			Out:          p.Secrets.Writer(&logs),
+			NoColor:      true,
+			PartsExclude: []string{zerolog.TimestampFieldName},
+		})
+
+		return ctx, err
+	}
+
+	result.Stdout, result.Stderr = capture(h.tb, func() {
+		result.Result = p.RunContext(h.tb.Context(), append([]string{p.App.Name}, args...))
+	})
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	result.Logs = logs.String()
+	result.Commands = slices.Clone(h.commands)
+	result.Outputs = p.Outputs.Values()
+	result.Report = p.Report.String()
+
+	return result
+}
+
+func (h *Harness) runCommand(cmd *exec.Cmd) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.commands = append(h.commands, slices.Clone(cmd.Args))
+
+	name := filepath.Base(cmd.Args[0])
+
+	results, ok := h.stubs[name]
+	if !ok || len(results) == 0 {
+		return fmt.Errorf("%w: %s", ErrUnexpectedCommand, strings.Join(cmd.Args, " "))
+	}
+
+	res := results[0]
+	if len(results) > 1 {
+		h.stubs[name] = results[1:]
+	}
+
+	if cmd.Stdout != nil {
+		_, _ = io.WriteString(cmd.Stdout, res.Stdout)
+	}
+
+	if cmd.Stderr != nil {
+		_, _ = io.WriteString(cmd.Stderr, res.Stderr)
+	}
+
+	if res.Err != nil {
+		return res.Err
+	}
+
+	if res.ExitCode != 0 {
+		return &ExitError{Code: res.ExitCode}
+	}
+
+	return nil
+}
+
+// capture redirects the standard output and error while running fn and returns
+// the captured output.
+func capture(tb testing.TB, fn func()) (string, string) {
+	tb.Helper()
+
+	stdout, stderr := os.Stdout, os.Stderr
+
+	defer func() {
+		os.Stdout, os.Stderr = stdout, stderr
+	}()
+
+	var (
+		wg             sync.WaitGroup
+		outBuf, errBuf bytes.Buffer
+	)
+
+	outWriter := pipe(tb, &wg, &outBuf)
+	errWriter := pipe(tb, &wg, &errBuf)
+
+	os.Stdout, os.Stderr = outWriter, errWriter
+
+	fn()
+
+	outWriter.Close()
+	errWriter.Close()
+	wg.Wait()
+
+	return outBuf.String(), errBuf.String()
+}
+
+func pipe(tb testing.TB, wg *sync.WaitGroup, buf *bytes.Buffer) *os.File {
+	tb.Helper()
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		tb.Fatalf("failed to create pipe: %v", err)
+	}
+
+	wg.Go(func() {
+		_, _ = io.Copy(buf, reader)
+		reader.Close()
+	})
+
+	return writer
+}
@@ -0,0 +1,172 @@
+package plugintest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/thegeeklab/wp-plugin-go/v6/exec"
+	"github.com/thegeeklab/wp-plugin-go/v6/plugin"
+	"github.com/urfave/cli/v3"
+)
+
+func newTestPlugin() *plugin.Plugin {
+	var p *plugin.Plugin
+
+	p = plugin.New(plugin.Options{
+		Name: "dummy",
+		Execute: func(_ context.Context) error {
+			if p.Metadata.Curr.Tag == "" {
+				return plugin.Skip("no tag")
+			}
+
+			log.Info().Str("tag", p.Metadata.Curr.Tag).Msg("publish")
+			fmt.Fprintf(os.Stderr, "repo %s\n", p.Metadata.Repository.Slug)
+
+			cmd := exec.Command("git", "push", "origin", p.Metadata.Curr.Tag)
+			cmd.Stdout = os.Stdout
+
+			return cmd.Run()
+		},
+	})
+
+	return p
+}
+
+func TestHarnessRun(t *testing.T) {
+	tests := []struct {
+		name         string
+		metadata     plugin.Metadata
+		stub         *CommandResult
+		wantStatus   plugin.Status
+		wantExitCode int
+		wantStdout   string
+		wantLogs     string
+		wantCommands [][]string
+	}{
+		{
+			name: "success",
+			metadata: plugin.Metadata{
+				Repository: plugin.Repository{Slug: "octocat/hello-world"},
+				Curr:       plugin.Commit{Tag: "v1.0.0"},
+			},
+			stub:         &CommandResult{Stdout: "pushed\n"},
+			wantStatus:   plugin.StatusSuccess,
+			wantStdout:   "+ git push origin v1.0.0\npushed\n",
+			wantLogs:     "publish tag=v1.0.0",
+			wantCommands: [][]string{{"git", "push", "origin", "v1.0.0"}},
+		},
+		{
+			name: "command failure",
+			metadata: plugin.Metadata{
+				Repository: plugin.Repository{Slug: "octocat/hello-world"},
+				Curr:       plugin.Commit{Tag: "v1.0.0"},
+			},
+			stub:         &CommandResult{ExitCode: 2},
+			wantStatus:   plugin.StatusFailure,
+			wantExitCode: 2,
+			wantLogs:     "execution failed",
+			wantCommands: [][]string{{"git", "push", "origin", "v1.0.0"}},
+		},
+		{
+			name:         "unexpected command",
+			metadata:     plugin.Metadata{Curr: plugin.Commit{Tag: "v1.0.0"}},
+			wantStatus:   plugin.StatusFailure,
+			wantLogs:     "unexpected command",
+			wantExitCode: plugin.ExitCodeInternal,
+			wantCommands: [][]string{{"git", "push", "origin", "v1.0.0"}},
+		},
+		{
+			name:       "skipped",
+			wantStatus: plugin.StatusSkipped,
+			wantLogs:   "execution skipped reason=\"no tag\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := New(t).WithMetadata(tt.metadata)
+			if tt.stub != nil {
+				h.StubCommand("git", *tt.stub)
+			}
+
+			got := h.Run(newTestPlugin())
+
+			assert.Equal(t, tt.wantStatus, got.Status)
+			assert.Equal(t, tt.wantExitCode, got.ExitCode)
+			assert.Contains(t, got.Logs, tt.wantLogs)
+			assert.Equal(t, tt.wantCommands, got.Commands)
+
+			if tt.wantStdout != "" {
+				assert.Equal(t, tt.wantStdout, got.Stdout)
+				assert.Equal(t, "repo octocat/hello-world\n", got.Stderr)
+			}
+		})
+	}
+}
+
+func TestHarnessWithEnvFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "env")
+	content := "CI_REPO=octocat/hello-world\nCI_COMMIT_TAG=v2.0.0\n"
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	got := New(t).WithEnvFile(path).StubCommand("git").Run(newTestPlugin())
+
+	assert.Equal(t, plugin.StatusSuccess, got.Status)
+	assert.Equal(t, [][]string{{"git", "push", "origin", "v2.0.0"}}, got.Commands)
+}
+
+func TestHarnessSecrets(t *testing.T) {
+	var p *plugin.Plugin
+
+	h := New(t).WithEnv("PLUGIN_TOKEN", "s3cr3t").StubCommand("curl")
+
+	p = plugin.New(plugin.Options{
+		Name: "dummy",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "token",
+				Sources: cli.EnvVars("PLUGIN_TOKEN"),
+			},
+		},
+		SecretFlags: []string{"token"},
+		Execute: func(_ context.Context) error {
+			token := p.App.String("token")
+
+			log.Info().Str("token", token).Msg("login")
+
+			return exec.Command("curl", "-H", "Authorization: Bearer "+token, "https://example.com").Run()
+		},
+	})
+
+	got := h.Run(p)
+
+	assert.Equal(t, plugin.StatusSuccess, got.Status)
+	assert.Contains(t, got.Logs, "login token=********")
+	assert.Equal(t, "+ curl -H Authorization: Bearer ******** https://example.com\n", got.Stdout)
+	assert.NotContains(t, got.Logs+got.Stdout, "s3cr3t")
+}
+
+func TestHarnessStubCombinedOutput(t *testing.T) {
+	var output string
+
+	p := plugin.New(plugin.Options{
+		Name: "dummy",
+		Execute: func(_ context.Context) error {
+			out, err := exec.Command("git", "describe", "--tags").CombinedOutput()
+			output = string(out)
+
+			return err
+		},
+	})
+
+	got := New(t).StubCommand("git", CommandResult{Stdout: "v1.0.0\n", Stderr: "warning\n"}).Run(p)
+
+	assert.Equal(t, plugin.StatusSuccess, got.Status)
+	assert.Equal(t, "v1.0.0\nwarning\n", output)
+	assert.Equal(t, [][]string{{"git", "describe", "--tags"}}, got.Commands)
+}